@@ -0,0 +1,81 @@
+// Package analyzer provides an analysis.Analyzer that checks f3_validate
+// struct tags, so problems show up in gopls and go vet -vettool.
+package analyzer
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"sandbox.io/tags/lint"
+	"sandbox.io/tags/tags"
+)
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "f3validate",
+	Doc:      "check that f3_validate struct tags compile and make sense for their field",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	inspect.Preorder([]ast.Node{(*ast.File)(nil)}, func(node ast.Node) {
+		for _, field := range lint.FindTaggedFields(node.(*ast.File)) {
+			for _, problem := range lint.CheckFields(field, structFields(pass, field.Struct)) {
+				pass.Reportf(field.Pos(problem.Position), "f3_validate: %s", problem.Summary())
+			}
+		}
+	})
+
+	return nil, nil
+}
+
+// structFields returns the fields of a struct with the kinds type checking
+// tells, or those the source tells when it was not type checked.
+func structFields(pass *analysis.Pass, structType *ast.StructType) []tags.StructField {
+	checked, ok := pass.TypesInfo.TypeOf(structType).(*types.Struct)
+	if !ok {
+		return lint.StructFields(structType)
+	}
+
+	qualifier := types.RelativeTo(pass.Pkg)
+	fields := make([]tags.StructField, 0, checked.NumFields())
+	for index := 0; index < checked.NumFields(); index++ {
+		field := checked.Field(index)
+		fields = append(fields, tags.StructField{Name: field.Name(), Type: types.TypeString(field.Type(), qualifier), Kind: kindOf(field)})
+	}
+	return fields
+}
+
+// kindOf returns the kind of a field as reflection sees it: that of the
+// underlying type, but time.Time itself and only when exported.
+func kindOf(field *types.Var) tags.FieldKind {
+	if named, ok := field.Type().(*types.Named); ok {
+		if object := named.Obj(); object.Pkg() != nil && object.Pkg().Path() == "time" && object.Name() == "Time" {
+			if !field.Exported() {
+				return tags.OtherKind
+			}
+			return tags.TimeKind
+		}
+	}
+
+	basic, ok := field.Type().Underlying().(*types.Basic)
+	switch {
+	case !ok:
+		return tags.OtherKind
+	case basic.Info()&types.IsString != 0:
+		return tags.StringKind
+	case basic.Info()&types.IsUnsigned != 0:
+		return tags.UintKind
+	case basic.Info()&types.IsInteger != 0:
+		return tags.IntKind
+	case basic.Info()&types.IsFloat != 0:
+		return tags.FloatKind
+	}
+	return tags.OtherKind
+}
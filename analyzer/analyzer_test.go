@@ -0,0 +1,11 @@
+package analyzer
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func Test_AnalyzerReportsTagProblems(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "accounts")
+}
@@ -0,0 +1,26 @@
+package accounts
+
+import "time"
+
+type IBAN string
+
+type Cents int64
+
+type Account struct {
+	Country   string
+	BankId    string    `f3_validate:"[GB:7-10,required | PT:5]"`
+	IBAN      IBAN      `f3_validate:"[GB:22 | PT:25]"`
+	Sortcode  string    `f3_validate:"[GB->6]"`                    // want `f3_validate: unexpected - symbol in position 3, expected '=', '/', ',' or ':'`
+	Swift     string    `f3_validate:"[GB:11-8]"`                  // want `f3_validate: minimum length 11 greater than maximum length 8 in position 4`
+	Name      string    `f3_validate:"[GB:0]"`                     // want `f3_validate: maximum length is zero in position 4`
+	Address   string    `f3_validate:"[UK:1-35]"`                  // want `f3_validate: unknown country UK in position 1`
+	City      string    `f3_validate:"[GB:required, 5, required]"` // want `f3_validate: rule required given twice in position 17`
+	Balance   int       `f3_validate:"[GB:1-10]"`                  // want `f3_validate: rule length cannot check a field of type int in position 4, expected string`
+	Tags      []string  `f3_validate:"[GB:required]"`              // want `f3_validate: rule required cannot check a field of type \[\]string in position 4, expected string`
+	Limit     Cents     `f3_validate:"[GB:gtfield(Balance)]"`
+	Overdraft Cents     `f3_validate:"[GB:trim]"` // want `f3_validate: rule trim cannot normalize a field of type Cents in position 4, expected string`
+	Confirm   IBAN      `f3_validate:"[GB:eqfield(IBAN)]"`
+	Opened    time.Time `f3_validate:"[GB:ltfield(Closed)]"`
+	Closed    time.Time `f3_validate:"[GB:gtfield(Country)]"`   // want `f3_validate: rule gtfield cannot compare with field Country of type string in position 12, expected time.Time`
+	Holder    string    `f3_validate:"[GB:required_if(Owner)]"` // want `f3_validate: rule required_if refers to unknown field Owner in position 16`
+}
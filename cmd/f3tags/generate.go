@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sandbox.io/tags/lint"
+	"sandbox.io/tags/tags"
+)
+
+func runGenerate(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	output := flags.String("output", "f3_validate_gen.go", "file to write, relative to the package directory")
+	typeNames := flags.String("types", "", "comma separated types to generate ValidateF3 for, e.g. Account,accounts.Payment; all by default")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 1 {
+		fmt.Fprintln(stderr, "f3tags: generate takes a single package directory")
+		return 2
+	}
+
+	dir := "."
+	if flags.NArg() == 1 {
+		dir = flags.Arg(0)
+	}
+
+	packageName, tables, diagnostics, err := validatorTables(dir, *typeNames)
+	if err != nil {
+		fmt.Fprintf(stderr, "f3tags: %v\n", err)
+		return 2
+	}
+	if len(diagnostics) > 0 {
+		for _, diagnostic := range diagnostics {
+			fmt.Fprintln(stderr, diagnostic)
+		}
+		return 1
+	}
+
+	var source bytes.Buffer
+	if err := tags.WriteValidators(&source, packageName, tables...); err != nil {
+		fmt.Fprintf(stderr, "f3tags: %v\n", err)
+		return 2
+	}
+	path := *output
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	if err := os.WriteFile(path, source.Bytes(), 0o644); err != nil {
+		fmt.Fprintf(stderr, "f3tags: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// validatorTables compiles the tagged fields of the package in dir into one
+// table per struct, in source order, keeping only the structs named in
+// typeNames if any. ValidateF3 methods are only generated for the fields of
+// named structs whose kind the source tells, strings, numbers and times.
+func validatorTables(dir, typeNames string) (string, []*tags.MatrixTable, []lint.Diagnostic, error) {
+	fileSet := token.NewFileSet()
+	files, err := lint.ParseFiles(fileSet, []string{dir}, false)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if len(files) == 0 {
+		return "", nil, nil, fmt.Errorf("no Go files in %s", dir)
+	}
+
+	wanted := wantedTypes(typeNames)
+	var tables []*tags.MatrixTable
+	var diagnostics []lint.Diagnostic
+	tablesByType := make(map[string]*tags.MatrixTable)
+
+	for _, file := range files {
+		for _, field := range lint.FindTaggedFields(file) {
+			if !wanted(field.StructType) {
+				continue
+			}
+			if strings.Count(field.StructType, ".") != 1 {
+				return "", nil, nil, fmt.Errorf("%s: cannot generate ValidateF3 for field %s of anonymous struct %s", fileSet.Position(field.Field.Pos()), field.FieldName, field.StructType)
+			}
+			kinds := make(map[string]tags.FieldKind)
+			for _, structField := range lint.StructFields(field.Struct) {
+				kinds[structField.Name] = structField.Kind
+			}
+			for _, name := range field.Field.Names {
+				switch kinds[name.Name] {
+				case tags.StringKind, tags.IntKind, tags.UintKind, tags.FloatKind, tags.TimeKind:
+				default:
+					return "", nil, nil, fmt.Errorf("%s: cannot generate ValidateF3 for field %s.%s of type %s, only fields of type string, time.Time or of a numeric type are validated", fileSet.Position(field.Field.Pos()), field.StructType, name.Name, field.FieldType)
+				}
+			}
+
+			validationInfos, diagnostic := compileField(fileSet, field)
+			if diagnostic != nil {
+				diagnostics = append(diagnostics, *diagnostic)
+				continue
+			}
+
+			table := tablesByType[field.StructType]
+			if table == nil {
+				table = &tags.MatrixTable{TypeName: field.StructType, Matrix: make(tags.ValidationMatrix), Kinds: make(map[string]tags.FieldKind)}
+				tablesByType[field.StructType] = table
+				tables = append(tables, table)
+			}
+			for _, name := range field.Field.Names {
+				validationInfos := validationInfos
+				table.Fields = append(table.Fields, name.Name)
+				table.Matrix[name.Name] = &validationInfos
+				if kind := kinds[name.Name]; kind != tags.StringKind {
+					table.Kinds[name.Name] = kind
+				}
+			}
+		}
+	}
+
+	return files[0].Name.Name, tables, diagnostics, nil
+}
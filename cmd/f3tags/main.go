@@ -0,0 +1,87 @@
+// Command f3tags works on the f3_validate struct tags of Go packages.
+//
+//	f3tags lint [-tests] [packages]
+//	f3tags matrix [-format markdown|csv|html] [-types Account,Payment] [packages]
+//	f3tags generate [-output f3_validate_gen.go] [-types Account,Payment] [package]
+//
+// lint compiles every f3_validate tag found in the packages, ./... by default,
+// prints a file:line:col diagnostic for each invalid one and exits with status 1
+// when any was found. _test.go files, which may hold invalid tags on purpose,
+// are only linted with -tests.
+//
+// matrix documents the rules of the tagged structs of the packages as a table
+// per type, with a row per field and a column per country.
+//
+// generate writes a ValidateF3 method for the tagged structs of a package, the
+// current directory by default, with the rules of the tags inlined, so the
+// structs are validated without reflection. It is meant for go generate:
+//
+//	//go:generate go run sandbox.io/tags/cmd/f3tags generate
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"sandbox.io/tags/lint"
+)
+
+const usage = `usage: f3tags <command> [arguments]
+
+commands:
+  lint      report invalid f3_validate tags
+  matrix    document the rules of tagged structs per field and country
+  generate  write ValidateF3 methods validating tagged structs without reflection
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	switch args[0] {
+	case "lint":
+		return runLint(args[1:], stdout, stderr)
+	case "matrix":
+		return runMatrix(args[1:], stdout, stderr)
+	case "generate":
+		return runGenerate(args[1:], stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "f3tags: unknown command %q\n%s", args[0], usage)
+		return 2
+	}
+}
+
+func runLint(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	includeTests := flags.Bool("tests", false, "also lint _test.go files")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	patterns := flags.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	diagnostics, err := lint.Run(patterns, *includeTests)
+	if err != nil {
+		fmt.Fprintf(stderr, "f3tags: %v\n", err)
+		return 2
+	}
+	for _, diagnostic := range diagnostics {
+		fmt.Fprintln(stdout, diagnostic)
+	}
+	if len(diagnostics) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RunExitStatus(t *testing.T) {
+	cases := []struct {
+		description    string
+		args           []string
+		expectedStatus int
+	}{
+		{description: "invalid tags fail the lint", args: []string{"lint", "../../lint/testdata/accounts/nested"}, expectedStatus: 1},
+		{description: "valid tags pass the lint", args: []string{"lint", "../../tags"}, expectedStatus: 0},
+		{description: "invalid tags of tests fail the lint of tests", args: []string{"lint", "-tests", "../../tags"}, expectedStatus: 1},
+		{description: "missing command", args: nil, expectedStatus: 2},
+		{description: "unknown command", args: []string{"fmt"}, expectedStatus: 2},
+		{description: "missing package", args: []string{"lint", "./missing"}, expectedStatus: 2},
+		{description: "matrix of valid tags", args: []string{"matrix", "testdata/accounts"}, expectedStatus: 0},
+		{description: "matrix of invalid tags", args: []string{"matrix", "../../lint/testdata/accounts/nested"}, expectedStatus: 1},
+		{description: "matrix in unknown format", args: []string{"matrix", "-format", "pdf", "testdata/accounts"}, expectedStatus: 2},
+		{description: "generate for invalid tags", args: []string{"generate", "../../lint/testdata/accounts/nested"}, expectedStatus: 1},
+		{description: "generate for fields of types that are not validated", args: []string{"generate", "testdata/amounts"}, expectedStatus: 2},
+		{description: "generate for several packages", args: []string{"generate", "testdata/accounts", "testdata/amounts"}, expectedStatus: 2},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			assert.Equal(t, c.expectedStatus, run(c.args, &stdout, &stderr))
+		})
+	}
+}
+
+func Test_LintPassesOnTheModule(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, 0, run([]string{"lint", "../../..."}, &stdout, &stderr))
+	assert.Empty(t, stdout.String())
+	assert.Empty(t, stderr.String())
+}
+
+func Test_MatrixDocumentsSelectedTypes(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	status := run([]string{"matrix", "-format", "csv", "-types", "Payment", "testdata/accounts"}, &stdout, &stderr)
+
+	assert.Equal(t, 0, status)
+	assert.Equal(t, "Type,Field,GB\naccounts.Payment,Reference,1 to 18 bytes\n", stdout.String())
+}
+
+func Test_MatrixDocumentsFieldsInSourceOrder(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	status := run([]string{"matrix", "-types", "accounts.Account", "testdata/accounts"}, &stdout, &stderr)
+
+	assert.Equal(t, 0, status)
+	assert.Equal(t, `## accounts.Account
+
+| Field | GB | IE | PT |
+| --- | --- | --- | --- |
+| BankId | required, 7 to 10 bytes |  | exactly 5 bytes |
+| IBAN | exactly 22 bytes | required, exactly 22 bytes |  |
+`, stdout.String())
+}
+
+func Test_GenerateWritesValidatorsOfExamples(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	output := filepath.Join(t.TempDir(), "f3_validate_gen.go")
+
+	status := run([]string{"generate", "-output", output, "../../examples/payments"}, &stdout, &stderr)
+
+	assert.Equal(t, 0, status, stderr.String())
+	generated, err := os.ReadFile(output)
+	assert.Nil(t, err)
+	expected, err := os.ReadFile("../../examples/payments/f3_validate_gen.go")
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), string(generated), "run go generate ./examples/...")
+}
+
+func Test_GenerateRejectsRulesTheTypesOfFieldsDoNotTake(t *testing.T) {
+	cases := []struct {
+		description    string
+		typeName       string
+		expectedStatus int
+		expectedStderr string
+	}{
+		{
+			description:    "rule checking a number",
+			typeName:       "Amount",
+			expectedStatus: 1,
+			expectedStderr: "testdata/amounts/amounts.go:5:36: field amounts.Amount.Value (int): rule required cannot check a field of type int in position 4, expected string\n\t[GB:required]\n\t    ^\n",
+		},
+		{
+			description:    "field of a type that is not validated",
+			typeName:       "Settlement",
+			expectedStatus: 2,
+			expectedStderr: "f3tags: testdata/amounts/amounts.go:10:2: cannot generate ValidateF3 for field amounts.Settlement.Settled of type bool, only fields of type string, time.Time or of a numeric type are validated\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			status := run([]string{"generate", "-types", c.typeName, "-output", filepath.Join(t.TempDir(), "f3_validate_gen.go"), "testdata/amounts"}, &stdout, &stderr)
+
+			assert.Equal(t, c.expectedStatus, status)
+			assert.Equal(t, c.expectedStderr, stderr.String())
+		})
+	}
+}
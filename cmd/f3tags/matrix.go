@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"strings"
+
+	"sandbox.io/tags/lint"
+	"sandbox.io/tags/tags"
+)
+
+func runMatrix(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("matrix", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", string(tags.MarkdownDocument), "document format: markdown, csv or html")
+	typeNames := flags.String("types", "", "comma separated types to document, e.g. Account,accounts.Payment; all by default")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	patterns := flags.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	tables, diagnostics, err := matrixTables(patterns, *typeNames)
+	if err != nil {
+		fmt.Fprintf(stderr, "f3tags: %v\n", err)
+		return 2
+	}
+	if len(diagnostics) > 0 {
+		for _, diagnostic := range diagnostics {
+			fmt.Fprintln(stderr, diagnostic)
+		}
+		return 1
+	}
+
+	if err := tags.WriteMatrixDocument(stdout, tags.DocumentFormat(*format), tables...); err != nil {
+		fmt.Fprintf(stderr, "f3tags: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// matrixTables compiles the tagged fields of the packages into one table per
+// struct, in source order, keeping only the structs named in typeNames if any.
+func matrixTables(patterns []string, typeNames string) ([]*tags.MatrixTable, []lint.Diagnostic, error) {
+	fileSet := token.NewFileSet()
+	files, err := lint.ParseFiles(fileSet, patterns, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wanted := wantedTypes(typeNames)
+	var tables []*tags.MatrixTable
+	var diagnostics []lint.Diagnostic
+	tablesByType := make(map[string]*tags.MatrixTable)
+
+	for _, file := range files {
+		for _, field := range lint.FindTaggedFields(file) {
+			if !wanted(field.StructType) {
+				continue
+			}
+
+			validationInfos, diagnostic := compileField(fileSet, field)
+			if diagnostic != nil {
+				diagnostics = append(diagnostics, *diagnostic)
+				continue
+			}
+
+			table := tablesByType[field.StructType]
+			if table == nil {
+				table = &tags.MatrixTable{TypeName: field.StructType, Matrix: make(tags.ValidationMatrix)}
+				tablesByType[field.StructType] = table
+				tables = append(tables, table)
+			}
+			table.Fields = append(table.Fields, field.FieldName)
+			table.Matrix[field.FieldName] = &validationInfos
+		}
+	}
+
+	return tables, diagnostics, nil
+}
+
+// compileField compiles the tag of a field, or returns the diagnostic of its error.
+func compileField(fileSet *token.FileSet, field lint.TaggedField) (tags.CountriesValidationInfos, *lint.Diagnostic) {
+	validationInfos, err := tags.CompileCountriesValidationInfos(field.Tag)
+	if err != nil {
+		tagError := err.(*tags.TagError)
+		tagError.StructType, tagError.FieldName, tagError.FieldType = field.StructType, field.FieldName, field.FieldType
+		return nil, &lint.Diagnostic{Pos: fileSet.Position(field.Pos(tagError.Position)), Field: field, Err: tagError}
+	}
+
+	// The rules are checked against the fields of the struct, as
+	// CreateValidationMatrix checks them.
+	matrix := make(tags.ValidationMatrix)
+	for _, name := range field.Field.Names {
+		matrix[name.Name] = &validationInfos
+	}
+	if err := tags.CheckStructFields(field.StructType, lint.StructFields(field.Struct), matrix); err != nil {
+		tagError := err.(*tags.TagError)
+		return nil, &lint.Diagnostic{Pos: fileSet.Position(field.Pos(tagError.Position)), Field: field, Err: tagError}
+	}
+	return validationInfos, nil
+}
+
+// wantedTypes returns whether a struct is one of the comma separated typeNames,
+// by full or short name, or any struct when typeNames is empty.
+func wantedTypes(typeNames string) func(structType string) bool {
+	wanted := make(map[string]bool)
+	for _, typeName := range strings.Split(typeNames, ",") {
+		if typeName = strings.TrimSpace(typeName); typeName != "" {
+			wanted[typeName] = true
+		}
+	}
+	return func(structType string) bool {
+		shortName := structType[strings.LastIndex(structType, ".")+1:]
+		return len(wanted) == 0 || wanted[structType] || wanted[shortName]
+	}
+}
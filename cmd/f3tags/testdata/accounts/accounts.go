@@ -0,0 +1,11 @@
+package accounts
+
+type Account struct {
+	Country string
+	BankId  string `f3_validate:"[GB:7-10,required | PT:5]"`
+	IBAN    string `f3_validate:"[GB:22 | IE:22,required]"`
+}
+
+type Payment struct {
+	Reference string `f3_validate:"[GB:1-18]"`
+}
@@ -0,0 +1,11 @@
+package amounts
+
+type Amount struct {
+	Currency string `f3_validate:"[GB:oneof(GBP)]"`
+	Value    int    `f3_validate:"[GB:required]"`
+}
+
+type Settlement struct {
+	Pending bool
+	Settled bool `f3_validate:"[GB:nefield(Pending)]"`
+}
@@ -0,0 +1,14 @@
+// Command f3vet runs the f3validate analyzer, standalone or through
+//
+//	go vet -vettool=$(which f3vet) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"sandbox.io/tags/analyzer"
+)
+
+func main() {
+	singlechecker.Main(analyzer.Analyzer)
+}
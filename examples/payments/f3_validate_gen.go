@@ -0,0 +1,305 @@
+// Code generated by f3tags generate; DO NOT EDIT.
+
+package payments
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"time"
+
+	"sandbox.io/tags/tags"
+)
+
+var (
+	f3AccountBICGBPattern       = regexp.MustCompile("^[A-Z0-9]+$")
+	f3AccountSortCodeGBPattern  = regexp.MustCompile("^[0-9]{6}$")
+	f3AccountBankIdIEPattern    = regexp.MustCompile("^[A-Z]+$")
+	f3AccountBICIEPattern       = regexp.MustCompile("^[A-Z0-9]+$")
+	f3PaymentReferencePTPattern = regexp.MustCompile("^[0-9]*$")
+	f3PaymentPurposePTPattern   = regexp.MustCompile("^[0-9]*$")
+)
+
+// ValidateF3 returns the validation errors of Account for a country, as tags.Validate does.
+func (a *Account) ValidateF3(country string) tags.ValidationErrors {
+	var validationErrors tags.ValidationErrors
+	switch country {
+	case "GB":
+		if a.Country == "" {
+			validationErrors = append(validationErrors, fmt.Sprintf("field %s is required when %s", "Country", "country is GB"))
+		} else {
+			switch a.Country {
+			case "GB":
+			default:
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must be one of %s when %s but found %s", "Country", "GB", "country is GB", a.Country))
+			}
+		}
+		if a.BankId == "" {
+			validationErrors = append(validationErrors, fmt.Sprintf("field %s is required when %s", "BankId", "country is GB"))
+		} else {
+			if length := len(a.BankId); length < 7 || length > 10 {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must have size from %d to %d %s when %s but found size %d", "BankId", 7, 10, "bytes", "country is GB", length))
+			}
+		}
+		if a.BankIdCode != "" {
+			switch a.BankIdCode {
+			case "GBDSC", "IENCC":
+			default:
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must be one of %s when %s but found %s", "BankIdCode", "GBDSC, IENCC, ", "country is GB", a.BankIdCode))
+			}
+		}
+		if a.IBAN == "" {
+			if !tags.IsSet(a.AccountNumber) {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s is required when %s and %s is not set", "IBAN", "country is GB", "AccountNumber"))
+			}
+		} else {
+			if length := len(a.IBAN); length < 22 || length > 22 {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must have size from %d to %d %s when %s but found size %d", "IBAN", 22, 22, "bytes", "country is GB", length))
+			}
+		}
+		normalizedConfirmIBAN := tags.ApplyNormalizers(a.ConfirmIBAN, "nospace", "upper")
+		if normalizedConfirmIBAN != "" {
+			if other := tags.ApplyNormalizers(a.IBAN, "nospace", "upper"); other != "" && !(normalizedConfirmIBAN == other) {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must %s field %s when %s but found %s and %s", "ConfirmIBAN", "equal", "IBAN", "country is GB", normalizedConfirmIBAN, other))
+			}
+		}
+		if a.AccountNumber != "" {
+			if length := len(a.AccountNumber); length < 6 || length > 8 {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must have size from %d to %d %s when %s but found size %d", "AccountNumber", 6, 8, "bytes", "country is GB", length))
+			}
+		}
+		normalizedBIC := tags.ApplyNormalizers(a.BIC, "trim", "upper")
+		if normalizedBIC != "" {
+			if length := len(normalizedBIC); length < 8 || length > 11 {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must have size from %d to %d %s when %s but found size %d", "BIC", 8, 11, "bytes", "country is GB", length))
+			}
+			if !f3AccountBICGBPattern.MatchString(normalizedBIC) {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must match %s when %s but found %s", "BIC", f3AccountBICGBPattern, "country is GB", normalizedBIC))
+			}
+		}
+		normalizedSortCode := tags.ApplyNormalizers(a.SortCode, "digitsonly")
+		if normalizedSortCode != "" {
+			if !f3AccountSortCodeGBPattern.MatchString(normalizedSortCode) {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must match %s when %s but found %s", "SortCode", f3AccountSortCodeGBPattern, "country is GB", normalizedSortCode))
+			}
+		}
+	case "IE":
+		if a.Country != "" {
+			switch a.Country {
+			case "IE", "PT":
+			default:
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must be one of %s when %s but found %s", "Country", "IE, PT, ", "country is IE", a.Country))
+			}
+		}
+		if a.BankId == "" {
+			if tags.IsSet(a.BankIdCode) {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s is required when %s and %s is set", "BankId", "country is IE", "BankIdCode"))
+			}
+		} else {
+			if length := len(a.BankId); length < 4 || length > 6 {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must have size from %d to %d %s when %s but found size %d", "BankId", 4, 6, "bytes", "country is IE", length))
+			}
+			if !f3AccountBankIdIEPattern.MatchString(a.BankId) {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must match %s when %s but found %s", "BankId", f3AccountBankIdIEPattern, "country is IE", a.BankId))
+			}
+		}
+		if a.BankIdCode != "" {
+			switch a.BankIdCode {
+			case "GBDSC", "IENCC":
+			default:
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must be one of %s when %s but found %s", "BankIdCode", "GBDSC, IENCC, ", "country is IE", a.BankIdCode))
+			}
+		}
+		if a.IBAN == "" {
+			validationErrors = append(validationErrors, fmt.Sprintf("field %s is required when %s", "IBAN", "country is IE"))
+		} else {
+			if length := len(a.IBAN); length < 22 || length > 22 {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must have size from %d to %d %s when %s but found size %d", "IBAN", 22, 22, "bytes", "country is IE", length))
+			}
+		}
+		normalizedConfirmIBAN := tags.ApplyNormalizers(a.ConfirmIBAN, "nospace", "upper")
+		if normalizedConfirmIBAN != "" {
+			if other := tags.ApplyNormalizers(a.IBAN, "nospace", "upper"); other != "" && !(normalizedConfirmIBAN == other) {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must %s field %s when %s but found %s and %s", "ConfirmIBAN", "equal", "IBAN", "country is IE", normalizedConfirmIBAN, other))
+			}
+		}
+		normalizedBIC := tags.ApplyNormalizers(a.BIC, "trim", "upper")
+		if normalizedBIC != "" {
+			if length := len(normalizedBIC); length < 8 || length > 11 {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must have size from %d to %d %s when %s but found size %d", "BIC", 8, 11, "bytes", "country is IE", length))
+			}
+			if !f3AccountBICIEPattern.MatchString(normalizedBIC) {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must match %s when %s but found %s", "BIC", f3AccountBICIEPattern, "country is IE", normalizedBIC))
+			}
+		}
+	case "PT":
+		if a.Country != "" {
+			switch a.Country {
+			case "IE", "PT":
+			default:
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must be one of %s when %s but found %s", "Country", "IE, PT, ", "country is PT", a.Country))
+			}
+		}
+		if a.BankId != "" {
+			if length := len(a.BankId); length < 5 || length > 5 {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must have size from %d to %d %s when %s but found size %d", "BankId", 5, 5, "bytes", "country is PT", length))
+			}
+		}
+	}
+	return validationErrors
+}
+
+// ValidateF3 returns the validation errors of Payment for a country, as tags.Validate does.
+func (p *Payment) ValidateF3(country string) tags.ValidationErrors {
+	var validationErrors tags.ValidationErrors
+	now := time.Now()
+	switch country {
+	case "GB":
+		if p.Currency != "" {
+			switch p.Currency {
+			case "GBP", "EUR":
+			default:
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must be one of %s when %s but found %s", "Currency", "GBP, EUR", "country is GB", p.Currency))
+			}
+		}
+		switch {
+		case now.Before(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)):
+			if p.Reference != "" {
+				if length := len(p.Reference); length < 1 || length > 18 {
+					validationErrors = append(validationErrors, fmt.Sprintf("field %s must have size from %d to %d %s when %s but found size %d", "Reference", 1, 18, "bytes", "country is GB", length))
+				}
+			}
+		case !now.Before(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)) && now.Before(time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)):
+			if p.Reference != "" {
+				if length := len(p.Reference); length < 1 || length > 35 {
+					validationErrors = append(validationErrors, fmt.Sprintf("field %s must have size from %d to %d %s when %s but found size %d", "Reference", 1, 35, "bytes", "country is GB", length))
+				}
+			}
+		case !now.Before(time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)):
+			if p.Reference != "" {
+				if length := len(p.Reference); length < 1 || length > 140 {
+					validationErrors = append(validationErrors, fmt.Sprintf("field %s must have size from %d to %d %s when %s but found size %d", "Reference", 1, 140, "bytes", "country is GB", length))
+				}
+			}
+		}
+		switch {
+		case now.Before(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)):
+			if p.Purpose != "" {
+				if length := len(p.Purpose); length < 1 || length > 18 {
+					validationErrors = append(validationErrors, fmt.Sprintf("field %s must have size from %d to %d %s when %s but found size %d", "Purpose", 1, 18, "bytes", "country is GB", length))
+				}
+			}
+		case !now.Before(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)) && now.Before(time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)):
+			if p.Purpose != "" {
+				if length := len(p.Purpose); length < 1 || length > 35 {
+					validationErrors = append(validationErrors, fmt.Sprintf("field %s must have size from %d to %d %s when %s but found size %d", "Purpose", 1, 35, "bytes", "country is GB", length))
+				}
+			}
+		case !now.Before(time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)):
+			if p.Purpose != "" {
+				if length := len(p.Purpose); length < 1 || length > 140 {
+					validationErrors = append(validationErrors, fmt.Sprintf("field %s must have size from %d to %d %s when %s but found size %d", "Purpose", 1, 140, "bytes", "country is GB", length))
+				}
+			}
+		}
+		if p.Beneficiary != "" {
+			if length := tags.Graphemes.Count(p.Beneficiary); length < 1 || length > 18 {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must have size from %d to %d %s when %s but found size %d", "Beneficiary", 1, 18, "graphemes", "country is GB", length))
+			}
+			for _, disallowed := range tags.MustLookupCharset("fps").Disallowed(p.Beneficiary) {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must only use characters of charset %s when %s but found %q in position %d", "Beneficiary", "fps", "country is GB", disallowed.Rune, disallowed.Position))
+			}
+		}
+	case "IE":
+		if p.Currency != "" {
+			switch p.Currency {
+			case "EUR":
+			default:
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must be one of %s when %s but found %s", "Currency", "EUR", "country is IE", p.Currency))
+			}
+		}
+		if p.Beneficiary != "" {
+			if length := tags.Runes.Count(p.Beneficiary); length < 1 || length > 35 {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must have size from %d to %d %s when %s but found size %d", "Beneficiary", 1, 35, "runes", "country is IE", length))
+			}
+			for _, disallowed := range tags.MustLookupCharset("sepa").Disallowed(p.Beneficiary) {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must only use characters of charset %s when %s but found %q in position %d", "Beneficiary", "sepa", "country is IE", disallowed.Rune, disallowed.Position))
+			}
+		}
+	case "PT":
+		if p.Currency != "" {
+			switch p.Currency {
+			case "EUR":
+			default:
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must be one of %s when %s but found %s", "Currency", "EUR", "country is PT", p.Currency))
+			}
+		}
+		switch {
+		case !now.Before(time.Date(2020, 6, 1, 11, 0, 0, 0, time.UTC)):
+			if p.Reference != "" {
+				if !f3PaymentReferencePTPattern.MatchString(p.Reference) {
+					validationErrors = append(validationErrors, fmt.Sprintf("field %s must match %s when %s but found %s", "Reference", f3PaymentReferencePTPattern, "country is PT", p.Reference))
+				}
+			}
+		}
+		switch {
+		case !now.Before(time.Date(2020, 6, 1, 11, 0, 0, 0, time.UTC)):
+			if p.Purpose != "" {
+				if !f3PaymentPurposePTPattern.MatchString(p.Purpose) {
+					validationErrors = append(validationErrors, fmt.Sprintf("field %s must match %s when %s but found %s", "Purpose", f3PaymentPurposePTPattern, "country is PT", p.Purpose))
+				}
+			}
+		}
+		if p.Beneficiary != "" {
+			if length := tags.Runes.Count(p.Beneficiary); length < 1 || length > 35 {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must have size from %d to %d %s when %s but found size %d", "Beneficiary", 1, 35, "runes", "country is PT", length))
+			}
+			for _, disallowed := range tags.MustLookupCharset("sepa").Disallowed(p.Beneficiary) {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must only use characters of charset %s when %s but found %q in position %d", "Beneficiary", "sepa", "country is PT", disallowed.Rune, disallowed.Position))
+			}
+		}
+	}
+	return validationErrors
+}
+
+// ValidateF3 returns the validation errors of StandingOrder for a country, as tags.Validate does.
+func (s *StandingOrder) ValidateF3(country string) tags.ValidationErrors {
+	var validationErrors tags.ValidationErrors
+	now := time.Now()
+	switch country {
+	case "GB":
+		if other := s.MinAmount; !(cmp.Compare(s.MaxAmount, other) > 0) {
+			validationErrors = append(validationErrors, fmt.Sprintf("field %s must %s field %s when %s but found %s and %s", "MaxAmount", "be greater than", "MinAmount", "country is GB", fmt.Sprint(int64(s.MaxAmount)), fmt.Sprint(int64(other))))
+		}
+		if other := s.Count; !(cmp.Compare(s.Installment, other) < 0) {
+			validationErrors = append(validationErrors, fmt.Sprintf("field %s must %s field %s when %s but found %s and %s", "Installment", "be less than", "Count", "country is GB", fmt.Sprint(uint64(s.Installment)), fmt.Sprint(uint64(other))))
+		}
+		if other := s.EndDate; !s.StartDate.IsZero() && !other.IsZero() && !(s.StartDate.Compare(other) < 0) {
+			validationErrors = append(validationErrors, fmt.Sprintf("field %s must %s field %s when %s but found %s and %s", "StartDate", "be less than", "EndDate", "country is GB", s.StartDate.Format(time.RFC3339Nano), other.Format(time.RFC3339Nano)))
+		}
+		if s.Reference == "" {
+			validationErrors = append(validationErrors, fmt.Sprintf("field %s is required when %s", "Reference", "country is GB"))
+		} else {
+			if length := len(s.Reference); length < 1 || length > 18 {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must have size from %d to %d %s when %s but found size %d", "Reference", 1, 18, "bytes", "country is GB", length))
+			}
+			if other := s.Note; other != "" && !(s.Reference != other) {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must %s field %s when %s but found %s and %s", "Reference", "differ from", "Note", "country is GB", s.Reference, other))
+			}
+		}
+	case "IE":
+		if other := s.MinAmount; !(cmp.Compare(s.MaxAmount, other) > 0) {
+			validationErrors = append(validationErrors, fmt.Sprintf("field %s must %s field %s when %s but found %s and %s", "MaxAmount", "be greater than", "MinAmount", "country is IE", fmt.Sprint(int64(s.MaxAmount)), fmt.Sprint(int64(other))))
+		}
+		switch {
+		case !now.Before(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)):
+			if other := s.AgreedRate; !(cmp.Compare(s.Rate, other) == 0) {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must %s field %s when %s but found %s and %s", "Rate", "equal", "AgreedRate", "country is IE", fmt.Sprint(float64(s.Rate)), fmt.Sprint(float64(other))))
+			}
+		}
+		if other := s.EndDate; !s.StartDate.IsZero() && !other.IsZero() && !(s.StartDate.Compare(other) < 0) {
+			validationErrors = append(validationErrors, fmt.Sprintf("field %s must %s field %s when %s but found %s and %s", "StartDate", "be less than", "EndDate", "country is IE", s.StartDate.Format(time.RFC3339Nano), other.Format(time.RFC3339Nano)))
+		}
+	}
+	return validationErrors
+}
@@ -0,0 +1,38 @@
+// Package payments shows the ValidateF3 methods f3tags generate writes for
+// tagged structs, and checks they validate as tags.Validate does.
+package payments
+
+import "time"
+
+//go:generate go run sandbox.io/tags/cmd/f3tags generate
+
+type Account struct {
+	Country       string `f3_validate:"[GB:oneof(GB),required | IE,PT:oneof(IE, PT, '')]"`
+	BankId        string `f3_validate:"[GB:7-10,required | PT:5 | IE:4-6,pattern('^[A-Z]+$'),required_if(BankIdCode)]"`
+	BankIdCode    string `f3_validate:"[GB,IE:oneof(GBDSC, IENCC, '')]"`
+	IBAN          string `f3_validate:"[GB:22,required_unless(AccountNumber) | IE:22,required]"`
+	ConfirmIBAN   string `f3_validate:"[GB,IE:nospace,upper,eqfield(IBAN)]"`
+	AccountNumber string `f3_validate:"[GB:6-8 | GB/FPS:8,required | currency=EUR:8-34]"`
+	BIC           string `f3_validate:"[GB,IE:trim,upper,8-11,pattern('^[A-Z0-9]+$')]"`
+	SortCode      string `f3_validate:"[GB:digitsonly,pattern('^[0-9]{6}$') | IE:trim]"`
+}
+
+type Payment struct {
+	Currency           string `f3_validate:"[GB:oneof(GBP, EUR) | PT,IE:oneof(EUR)]"`
+	Reference, Purpose string `f3_validate:"[GB:1-18,until(2020-01-01) | GB:1-35,since(2020-01-01),until(2099-01-01) | GB:1-140,since(2099-01-01) | PT:pattern('^[0-9]*$'),since('2020-06-01T12:00:00+01:00')]"`
+	Beneficiary        string `f3_validate:"[GB:graphemes:1-18,charset(fps) | PT,IE:runes:1-35,charset(sepa)]"`
+	Note               string
+}
+
+type StandingOrder struct {
+	MinAmount   int64
+	MaxAmount   int64  `f3_validate:"[GB,IE:gtfield(MinAmount)]"`
+	Installment uint16 `f3_validate:"[GB:ltfield(Count)]"`
+	Count       uint16
+	Rate        float32 `f3_validate:"[IE:eqfield(AgreedRate),since(2020-01-01)]"`
+	AgreedRate  float32
+	StartDate   time.Time `f3_validate:"[GB,IE:ltfield(EndDate)]"`
+	EndDate     time.Time
+	Reference   string `f3_validate:"[GB:1-18,required,nefield(Note)]"`
+	Note        string
+}
@@ -0,0 +1,65 @@
+package payments
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"sandbox.io/tags/tags"
+)
+
+type f3Validated interface {
+	ValidateF3(country string) tags.ValidationErrors
+}
+
+func Test_ValidateF3MatchesValidate(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	fixtures := []struct {
+		description string
+		value       f3Validated
+	}{
+		{description: "empty account", value: &Account{}},
+		{description: "valid GB account", value: &Account{Country: "GB", BankId: "1234567", IBAN: "GB29NWBK60161331926819", AccountNumber: "12345678"}},
+		{description: "invalid GB account", value: &Account{Country: "FR", BankId: "123", IBAN: "GB29", AccountNumber: "1234"}},
+		{description: "invalid IE account", value: &Account{Country: "GB", BankId: "ab1", AccountNumber: "1"}},
+		{description: "account with unnormalized codes", value: &Account{Country: "GB", BankId: "1234567", BIC: " nwbkgb2l ", SortCode: "60-16-13"}},
+		{description: "account with invalid codes", value: &Account{Country: "GB", BankId: "1234567", BIC: "nwbk-gb2l", SortCode: "60-16"}},
+		{description: "valid IE account", value: &Account{Country: "IE", BankId: "ABCD", IBAN: "IE29AIBK93115212345678"}},
+		{description: "account with a bank id code but no bank id", value: &Account{Country: "IE", BankIdCode: "IENCC"}},
+		{description: "account with a confirmed IBAN", value: &Account{Country: "IE", BankId: "ABCD", IBAN: "IE29AIBK93115212345678", ConfirmIBAN: "ie29 aibk 9311 5212 3456 78"}},
+		{description: "account with a mistyped IBAN confirmation", value: &Account{Country: "IE", BankId: "ABCD", IBAN: "IE29AIBK93115212345678", ConfirmIBAN: "IE29 AIBK 9311 5212 3456 87"}},
+		{description: "account with neither IBAN nor account number", value: &Account{Country: "GB", BankId: "1234567", BankIdCode: "GBDSC"}},
+		{description: "empty payment", value: &Payment{}},
+		{description: "valid payment", value: &Payment{Currency: "EUR", Reference: "12345", Purpose: "2026", Beneficiary: "José Müller"}},
+		{description: "payment to an accented name", value: &Payment{Currency: "EUR", Reference: "12345", Purpose: "2026", Beneficiary: "Zoë & Jürgen"}},
+		{description: "invalid payment", value: &Payment{Currency: "USD", Reference: "INVOICE 12345 OF THE 1ST OF MARCH 2026", Purpose: "rent", Beneficiary: "Zoë Ångström-Müller Nørgaard-Sørensen", Note: "not validated"}},
+		{description: "empty standing order", value: &StandingOrder{}},
+		{description: "valid standing order", value: &StandingOrder{MinAmount: 100, MaxAmount: 5000, Installment: 1, Count: 12, Rate: 0.1, AgreedRate: 0.1, StartDate: start, EndDate: start.AddDate(1, 0, 0), Reference: "RENT"}},
+		{description: "invalid standing order", value: &StandingOrder{MinAmount: 5000, MaxAmount: 100, Installment: 12, Count: 12, Rate: 0.1, AgreedRate: 0.2, StartDate: start, EndDate: start, Reference: "RENT", Note: "RENT"}},
+	}
+	countries := []string{"GB", "IE", "PT", "FR", ""}
+
+	for _, fixture := range fixtures {
+		for _, country := range countries {
+			t.Run(fixture.description+" "+country, func(t *testing.T) {
+				expected, err := tags.Validate(fixture.value, country)
+				assert.Nil(t, err)
+
+				actual := fixture.value.ValidateF3(country)
+
+				if expected == nil {
+					assert.Nil(t, actual)
+				} else {
+					assert.Equal(t, expected, []string(actual))
+				}
+			})
+		}
+	}
+}
+
+func Test_ValidationErrorsJoinsErrors(t *testing.T) {
+	validationErrors := (&Payment{Currency: "USD", Reference: "A", Beneficiary: "Muller"}).ValidateF3("PT")
+
+	assert.EqualError(t, validationErrors, "field Currency must be one of EUR when country is PT but found USD; field Reference must match ^[0-9]*$ when country is PT but found A")
+}
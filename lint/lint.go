@@ -0,0 +1,359 @@
+// Package lint finds f3_validate struct tags in Go source files and compiles
+// them, so malformed tags fail the build instead of the first Validate call.
+package lint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sandbox.io/tags/tags"
+)
+
+// TaggedField is a struct field carrying an f3_validate tag.
+type TaggedField struct {
+	StructType string
+	FieldName  string
+	FieldType  string
+	Tag        string
+	Field      *ast.Field
+	// Struct is the struct declaring the field, whose fields its rules name.
+	Struct *ast.StructType
+
+	// tagStart is the position of the first byte of the tag value, and
+	// exactOffsets tells whether the bytes of the value map one to one to the
+	// source, which is not the case when the struct tag contains escapes.
+	tagStart     token.Pos
+	exactOffsets bool
+}
+
+// Pos returns the source position of the byte at offset in the tag value.
+func (field TaggedField) Pos(offset int) token.Pos {
+	if field.exactOffsets {
+		return field.tagStart + token.Pos(offset)
+	}
+	return field.tagStart
+}
+
+// Diagnostic is a problem found in the tag of a field.
+type Diagnostic struct {
+	Pos   token.Position
+	Field TaggedField
+	Err   *tags.TagError
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s", d.Pos, d.Err.Error())
+}
+
+// Run lints the Go files matched by patterns. A pattern is a directory, a file,
+// or a directory followed by /... to include its subdirectories, like the go tool.
+func Run(patterns []string, includeTests bool) ([]Diagnostic, error) {
+	fileSet := token.NewFileSet()
+	files, err := ParseFiles(fileSet, patterns, includeTests)
+	if err != nil {
+		return nil, err
+	}
+
+	var diagnostics []Diagnostic
+	for _, file := range files {
+		for _, field := range FindTaggedFields(file) {
+			for _, problem := range Check(field) {
+				diagnostics = append(diagnostics, Diagnostic{Pos: fileSet.Position(field.Pos(problem.Position)), Field: field, Err: problem})
+			}
+		}
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		if diagnostics[i].Pos.Filename != diagnostics[j].Pos.Filename {
+			return diagnostics[i].Pos.Filename < diagnostics[j].Pos.Filename
+		}
+		return diagnostics[i].Pos.Offset < diagnostics[j].Pos.Offset
+	})
+	return diagnostics, nil
+}
+
+// Check compiles the tag of a field and returns the compile error, or the
+// problems found by tags.LintTag and by tags.CheckStructFields against the
+// fields of its struct, as StructFields reads them, when it compiles.
+func Check(field TaggedField) []*tags.TagError {
+	var fields []tags.StructField
+	if field.Struct != nil {
+		fields = StructFields(field.Struct)
+	}
+	return CheckFields(field, fields)
+}
+
+// CheckFields is Check with the fields of the struct of field given, e.g. with
+// the kinds type checking tells. The rules are not checked against the fields
+// when there are none.
+func CheckFields(field TaggedField, fields []tags.StructField) []*tags.TagError {
+	var problems []*tags.TagError
+	node, err := tags.ParseTag(field.Tag)
+	var validationInfos tags.CountriesValidationInfos
+	if err == nil {
+		validationInfos, err = tags.CompileTag(node)
+	}
+	if err != nil {
+		problems = append(problems, err.(*tags.TagError))
+	} else {
+		problems = tags.LintTag(node)
+		if problem := checkStructFields(field, fields, validationInfos); problem != nil {
+			problems = append(problems, problem)
+		}
+	}
+
+	for _, problem := range problems {
+		problem.StructType = field.StructType
+		problem.FieldName = field.FieldName
+		problem.FieldType = field.FieldType
+	}
+	return problems
+}
+
+// checkStructFields checks the rules of field against the fields of its
+// struct, the field standing for each of the names it declares.
+func checkStructFields(field TaggedField, fields []tags.StructField, validationInfos tags.CountriesValidationInfos) *tags.TagError {
+	if len(fields) == 0 {
+		return nil
+	}
+	names := []string{field.FieldName}
+	if field.Field != nil {
+		names = fieldNames(field.Field)
+	}
+	matrix := make(tags.ValidationMatrix)
+	for _, name := range names {
+		matrix[name] = &validationInfos
+	}
+	if err := tags.CheckStructFields(field.StructType, fields, matrix); err != nil {
+		return err.(*tags.TagError)
+	}
+	return nil
+}
+
+// ParseFiles parses the Go files matched by patterns, sorted by file name.
+func ParseFiles(fileSet *token.FileSet, patterns []string, includeTests bool) ([]*ast.File, error) {
+	paths, err := expandPatterns(patterns, includeTests)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*ast.File
+	for _, path := range paths {
+		file, err := parser.ParseFile(fileSet, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func expandPatterns(patterns []string, includeTests bool) ([]string, error) {
+	var paths []string
+	isGoFile := func(name string) bool {
+		return strings.HasSuffix(name, ".go") && (includeTests || !strings.HasSuffix(name, "_test.go"))
+	}
+
+	for _, pattern := range patterns {
+		if dir := strings.TrimSuffix(pattern, "/..."); dir != pattern {
+			err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				if info.IsDir() {
+					if path != dir && skipDir(info.Name()) {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if isGoFile(info.Name()) {
+					paths = append(paths, path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		info, err := os.Stat(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			paths = append(paths, pattern)
+			continue
+		}
+		entries, err := os.ReadDir(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && isGoFile(entry.Name()) {
+				paths = append(paths, filepath.Join(pattern, entry.Name()))
+			}
+		}
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// skipDir reports whether a directory is ignored by ./... the same way the go tool does.
+func skipDir(name string) bool {
+	return name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+// FindTaggedFields returns the fields of every struct in file that have an f3_validate tag.
+// Fields of anonymous structs are reported with the path of the field they type,
+// e.g. accounts.Beneficiary.Address, or with "struct" when there is none.
+func FindTaggedFields(file *ast.File) []TaggedField {
+	var fields []TaggedField
+	structNames := make(map[*ast.StructType]string)
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.TypeSpec:
+			if structType, ok := n.Type.(*ast.StructType); ok {
+				structNames[structType] = file.Name.Name + "." + n.Name.Name
+			}
+		case *ast.StructType:
+			structName, ok := structNames[n]
+			if !ok {
+				structName = "struct"
+			}
+			for _, field := range n.Fields.List {
+				if taggedField, ok := findTaggedField(structName, field); ok {
+					taggedField.Struct = n
+					fields = append(fields, taggedField)
+				}
+				if fieldStruct, ok := field.Type.(*ast.StructType); ok && len(field.Names) > 0 {
+					structNames[fieldStruct] = structName + "." + field.Names[0].Name
+				}
+			}
+		}
+		return true
+	})
+
+	return fields
+}
+
+func findTaggedField(structName string, field *ast.Field) (TaggedField, bool) {
+	if field.Tag == nil {
+		return TaggedField{}, false
+	}
+	structTag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return TaggedField{}, false
+	}
+	// Empty tags are skipped, as CreateValidationMatrix does.
+	value := reflect.StructTag(structTag).Get(tags.ValidationForm3TagName)
+	if len(value) == 0 {
+		return TaggedField{}, false
+	}
+
+	var names []string
+	for _, name := range field.Names {
+		names = append(names, name.Name)
+	}
+	if len(names) == 0 {
+		names = append(names, types.ExprString(field.Type))
+	}
+
+	taggedField := TaggedField{
+		StructType: structName,
+		FieldName:  strings.Join(names, ", "),
+		FieldType:  types.ExprString(field.Type),
+		Tag:        value,
+		Field:      field,
+		tagStart:   field.Tag.Pos(),
+	}
+
+	// In a raw string literal without escapes the value appears verbatim after its key.
+	key := tags.ValidationForm3TagName + `:"`
+	if start := strings.Index(field.Tag.Value, key+value+`"`); start >= 0 && field.Tag.Value[0] == '`' {
+		taggedField.tagStart += token.Pos(start + len(key))
+		taggedField.exactOffsets = true
+	}
+	return taggedField, true
+}
+
+// StructFields returns the fields structType declares, with the kinds their
+// types are of. Types declared elsewhere are of tags.UnknownKind, as only their
+// declaration tells, but time.Time fields are taken to be of package time.
+func StructFields(structType *ast.StructType) []tags.StructField {
+	var fields []tags.StructField
+	for _, field := range structType.Fields.List {
+		fieldType := types.ExprString(field.Type)
+		for _, name := range fieldNames(field) {
+			kind := tags.UnknownKind
+			if len(field.Names) > 0 {
+				// The type of an embedded field is named, so only its declaration tells its kind.
+				kind = exprKind(field.Type)
+			}
+			if kind == tags.TimeKind && !token.IsExported(name) {
+				kind = tags.OtherKind
+			}
+			fields = append(fields, tags.StructField{Name: name, Type: fieldType, Kind: kind})
+		}
+	}
+	return fields
+}
+
+// fieldNames returns the names a field declares, that of an embedded field
+// being the name of its type.
+func fieldNames(field *ast.Field) []string {
+	var names []string
+	for _, name := range field.Names {
+		names = append(names, name.Name)
+	}
+	if len(field.Names) == 0 {
+		fieldType := strings.TrimPrefix(types.ExprString(field.Type), "*")
+		if start := strings.IndexByte(fieldType, '['); start >= 0 {
+			fieldType = fieldType[:start]
+		}
+		names = append(names, fieldType[strings.LastIndex(fieldType, ".")+1:])
+	}
+	return names
+}
+
+// exprKind returns the kind of the values of a type expression.
+func exprKind(expr ast.Expr) tags.FieldKind {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		switch expr.Name {
+		case "string":
+			return tags.StringKind
+		case "int", "int8", "int16", "int32", "int64", "rune":
+			return tags.IntKind
+		case "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte":
+			return tags.UintKind
+		case "float32", "float64":
+			return tags.FloatKind
+		case "bool", "complex64", "complex128", "any", "error":
+			return tags.OtherKind
+		}
+		return tags.UnknownKind
+	case *ast.SelectorExpr:
+		if pkg, ok := expr.X.(*ast.Ident); ok && pkg.Name == "time" && expr.Sel.Name == "Time" {
+			return tags.TimeKind
+		}
+		return tags.UnknownKind
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		// An instance of a generic type declared elsewhere.
+		return tags.UnknownKind
+	case *ast.ParenExpr:
+		return exprKind(expr.X)
+	}
+	return tags.OtherKind
+}
@@ -0,0 +1,98 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sandbox.io/tags/tags"
+)
+
+func Test_RunReportsInvalidTagsWithTheirPosition(t *testing.T) {
+	diagnostics, err := Run([]string{"testdata/accounts/..."}, true)
+
+	assert.Nil(t, err)
+	var reported []string
+	for _, diagnostic := range diagnostics {
+		reported = append(reported, diagnostic.Pos.String()+" "+diagnostic.Field.StructType+"."+diagnostic.Field.FieldName)
+	}
+	assert.Equal(t, []string{
+		"testdata/accounts/accounts.go:6:34 accounts.Account.IBAN",
+		"testdata/accounts/accounts.go:12:37 accounts.Beneficiary.Address.Line",
+		"testdata/accounts/accounts.go:14:14 accounts.Beneficiary.Name",
+		"testdata/accounts/accounts_test.go:4:33 accounts.testAccount.BankId",
+		"testdata/accounts/nested/nested.go:4:44 nested.Payment.Reference",
+	}, reported)
+}
+
+func Test_RunHonoursPatterns(t *testing.T) {
+	cases := []struct {
+		description         string
+		patterns            []string
+		includeTests        bool
+		expectedDiagnostics int
+	}{
+		{description: "directory without subdirectories", patterns: []string{"testdata/accounts"}, includeTests: true, expectedDiagnostics: 4},
+		{description: "directory without tests", patterns: []string{"testdata/accounts"}, includeTests: false, expectedDiagnostics: 3},
+		{description: "single file", patterns: []string{"testdata/accounts/nested/nested.go"}, includeTests: false, expectedDiagnostics: 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			diagnostics, err := Run(c.patterns, c.includeTests)
+
+			assert.Nil(t, err)
+			assert.Equal(t, c.expectedDiagnostics, len(diagnostics))
+		})
+	}
+}
+
+func Test_DiagnosticStringIncludesPositionAndExcerpt(t *testing.T) {
+	diagnostics, err := Run([]string{"testdata/accounts/accounts.go"}, false)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "testdata/accounts/accounts.go:6:34: field accounts.Account.IBAN (string): unexpected - symbol in position 3, expected '=', '/', ',' or ':'\n\t[GB->8]\n\t   ^", diagnostics[0].String())
+}
+
+func Test_RunFailsOnMissingPath(t *testing.T) {
+	_, err := Run([]string{"testdata/missing"}, true)
+
+	assert.NotNil(t, err)
+}
+
+func Test_CheckReportsLintProblemsOfValidTags(t *testing.T) {
+	problems := Check(TaggedField{StructType: "accounts.Account", FieldName: "BankId", FieldType: "string", Tag: "[UK:7,required,required]"})
+
+	var reported []string
+	for _, problem := range problems {
+		reported = append(reported, string(problem.Code)+" "+problem.FieldName)
+	}
+	assert.Equal(t, []string{"UNKNOWN_COUNTRY BankId", "DUPLICATE_RULE BankId"}, reported)
+}
+
+func Test_RunChecksRulesAgainstTheFieldsOfTheirStruct(t *testing.T) {
+	diagnostics, err := Run([]string{"testdata/transfers"}, false)
+
+	assert.Nil(t, err)
+	var reported []string
+	for _, diagnostic := range diagnostics {
+		reported = append(reported, diagnostic.Pos.String()+" "+string(diagnostic.Err.Code)+" "+diagnostic.Err.Summary())
+	}
+	assert.Equal(t, []string{
+		"testdata/transfers/transfers.go:10:40 UNNORMALIZABLE_FIELD rule trim cannot normalize a field of type int in position 4, expected string",
+		"testdata/transfers/transfers.go:11:53 INCOMPARABLE_FIELD rule gtfield cannot compare with field Amount of type int in position 17, expected string",
+		"testdata/transfers/transfers.go:12:52 UNKNOWN_FIELD rule required_if refers to unknown field BankCode in position 16",
+		"testdata/transfers/transfers.go:13:40 UNSUPPORTED_FIELD rule length cannot check a field of type bool in position 4, expected string",
+	}, reported)
+}
+
+func Test_CheckFieldsTakesTheKindsOfTheFields(t *testing.T) {
+	field := TaggedField{StructType: "transfers.Transfer", FieldName: "Original", FieldType: "string", Tag: "[GB:eqfield(Currency)]"}
+
+	assert.Empty(t, CheckFields(field, []tags.StructField{{Name: "Original", Type: "string", Kind: tags.StringKind}, {Name: "Currency", Type: "Currency", Kind: tags.UnknownKind}}))
+
+	problems := CheckFields(field, []tags.StructField{{Name: "Original", Type: "string", Kind: tags.StringKind}, {Name: "Currency", Type: "Currency", Kind: tags.IntKind}})
+	assert.Len(t, problems, 1)
+	assert.Equal(t, tags.CodeIncomparableField, problems[0].Code)
+	assert.Equal(t, "Original", problems[0].FieldName)
+}
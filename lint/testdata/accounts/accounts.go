@@ -0,0 +1,15 @@
+package accounts
+
+type Account struct {
+	Country string
+	BankId  string `json:"bank_id" f3_validate:"[GB:7-10,required | PT:5]"`
+	IBAN    string `f3_validate:"[GB->8]"`
+	Empty   string `f3_validate:""`
+}
+
+type Beneficiary struct {
+	Address struct {
+		Line string `f3_validate:"[GB:1-35"`
+	}
+	Name string "f3_validate:\"[GB:+]\""
+}
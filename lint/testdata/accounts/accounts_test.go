@@ -0,0 +1,5 @@
+package accounts
+
+type testAccount struct {
+	BankId string `f3_validate:"[GB|]"`
+}
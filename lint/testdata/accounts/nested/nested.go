@@ -0,0 +1,5 @@
+package nested
+
+type Payment struct {
+	Reference string `f3_validate:"[GB:1-18 | GB:1-35]"`
+}
@@ -0,0 +1,5 @@
+package ignored
+
+type Ignored struct {
+	BankId string `f3_validate:"[GB|]"`
+}
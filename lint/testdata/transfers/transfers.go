@@ -0,0 +1,18 @@
+package transfers
+
+import "time"
+
+type Currency string
+
+type Transfer struct {
+	MinAmount int64
+	MaxAmount int64     `f3_validate:"[GB:gtfield(MinAmount)]"`
+	Amount    int       `f3_validate:"[GB:trim]"`
+	Reference string    `f3_validate:"[GB:1-18,gtfield(Amount)]"`
+	BankId    string    `f3_validate:"[IE:required_if(BankCode)]"`
+	Urgent    bool      `f3_validate:"[GB:1-3]"`
+	StartDate time.Time `f3_validate:"[GB:ltfield(EndDate)]"`
+	EndDate   time.Time
+	Currency  Currency
+	Original  string `f3_validate:"[GB:eqfield(Currency)]"`
+}
@@ -0,0 +1,40 @@
+#!/bin/sh
+# Compares the benchmarks of two revisions with benchstat:
+#
+#	scripts/benchstat.sh [base] [head]
+#
+# base defaults to main and head to the working tree, uncommitted changes
+# included. COUNT sets the runs of each benchmark, 10 by default, BENCH the
+# benchmarks to run, all by default, and PACKAGES the packages, ./tags by default.
+# benchstat is run with go run unless it is installed.
+set -eu
+
+base=${1:-main}
+head=${2:-}
+count=${COUNT:-10}
+bench=${BENCH:-.}
+packages=${PACKAGES:-./tags}
+
+root=$(git rev-parse --show-toplevel)
+work=$(mktemp -d)
+trap 'git -C "$root" worktree remove --force "$work/base" >/dev/null 2>&1 || true; git -C "$root" worktree remove --force "$work/head" >/dev/null 2>&1 || true; rm -rf "$work"' EXIT
+
+run() {
+	(cd "$1" && go test -run '^$' -bench "$bench" -benchmem -count "$count" $packages) | tee "$2"
+}
+
+git -C "$root" worktree add --detach "$work/base" "$base" >/dev/null
+run "$work/base" "$work/base.txt"
+
+if [ -n "$head" ]; then
+	git -C "$root" worktree add --detach "$work/head" "$head" >/dev/null
+	run "$work/head" "$work/head.txt"
+else
+	run "$root" "$work/head.txt"
+fi
+
+if command -v benchstat >/dev/null 2>&1; then
+	benchstat "base=$work/base.txt" "head=$work/head.txt"
+else
+	go run golang.org/x/perf/cmd/benchstat@latest "base=$work/base.txt" "head=$work/head.txt"
+fi
@@ -0,0 +1,131 @@
+package tags
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Run with go test -run '^$' -bench . -benchmem ./tags, or compare revisions
+// with scripts/benchstat.sh.
+
+const smallTag = "[GB,IE:7-10,required | PT:5]"
+
+// largeTag gives rules to every known country, with schemes, dimensions,
+// effective dates and rules with arguments.
+var largeTag = func() string {
+	codes := make([]string, 0, len(countryCodes))
+	for code := range countryCodes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	clauses := make([]string, 0, len(codes)+4)
+	for i, code := range codes {
+		clauses = append(clauses, fmt.Sprintf("%s:%d-%d,required", code, i%10+1, i%10+20))
+	}
+	clauses = append(clauses,
+		"GB/FPS,GB/BACS:6-8,pattern('^[0-9]+$')",
+		"GB,currency=EUR:oneof(EUR, GBP)",
+		"IE/SEPA_SCT:8,until(2026-03-01)",
+		"IE/SEPA_SCT:8-34,since(2026-03-01)",
+	)
+	return "[" + strings.Join(clauses, " | ") + "]"
+}()
+
+func BenchmarkCompileCountriesValidationInfos(b *testing.B) {
+	for _, c := range []struct {
+		name string
+		tag  string
+	}{{"small", smallTag}, {"large", largeTag}} {
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := CompileCountriesValidationInfos(c.tag); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// wideStruct returns a zero struct with the given number of tagged string fields.
+func wideStruct(fields int) interface{} {
+	structFields := make([]reflect.StructField, fields)
+	for i := range structFields {
+		structFields[i] = reflect.StructField{
+			Name: fmt.Sprintf("Field%d", i),
+			Type: reflect.TypeOf(""),
+			Tag:  reflect.StructTag(fmt.Sprintf(`f3_validate:"[GB:%d-%d,required | IE,PT:oneof(A%d, B%d) | GB/FPS:pattern('^[A-Z]+$')]"`, i%5+1, i%5+10, i, i)),
+		}
+	}
+	return reflect.New(reflect.StructOf(structFields)).Elem().Interface()
+}
+
+func BenchmarkCreateValidationMatrix(b *testing.B) {
+	for _, fields := range []int{8, 64} {
+		value := wideStruct(fields)
+		b.Run(fmt.Sprintf("fields=%d", fields), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := CreateValidationMatrix(value); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// beneficiary nests tagged structs, which are not validated themselves.
+type beneficiary struct {
+	Name    string `f3_validate:"[GB,IE:1-35,required | GB/FPS:1-18]"`
+	Account account
+	Address struct {
+		Line     string
+		PostCode string
+	}
+	Payee  *payee
+	Charge charge
+	Email  string `f3_validate:"[GB,IE,PT:pattern('^[^@]+@[^@]+$')]"`
+}
+
+func BenchmarkValidate(b *testing.B) {
+	for _, c := range []struct {
+		name       string
+		value      interface{}
+		dimensions Dimensions
+	}{
+		{"valid", &account{BankId: "1234567", IBAN: "12345678"}, Dimensions{CountryDimension: "GB"}},
+		{"invalid", &transfer{Currency: "USD", Reference: "invoice #42"}, Dimensions{CountryDimension: "GB"}},
+		{"nested", &beneficiary{Name: "Jane Doe", Account: account{BankId: "1"}, Payee: &payee{}, Email: "jane@example.com"}, Dimensions{CountryDimension: "GB", SchemeDimension: "FPS"}},
+		{"wide", wideStruct(64), Dimensions{CountryDimension: "PT"}},
+	} {
+		b.Run(c.name, func(b *testing.B) {
+			validator := mustValidator(b)
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := validator.Validate(ctx, c.value, c.dimensions); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkValidateWithoutCache(b *testing.B) {
+	value := &account{BankId: "1234567", IBAN: "12345678"}
+	ctx := context.Background()
+	dimensions := Dimensions{CountryDimension: "GB"}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := mustValidator(b).Validate(ctx, value, dimensions); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package tags
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"unicode"
+)
+
+// Charset is a set of characters a payment scheme accepts, checked by the
+// charset rule, e.g. charset(swiftx).
+type Charset struct {
+	name   string
+	ascii  [128]bool
+	others map[rune]bool
+}
+
+// NewCharset returns a charset allowing the characters of characters.
+func NewCharset(name, characters string) *Charset {
+	charset := &Charset{name: name, others: make(map[rune]bool)}
+	for _, r := range characters {
+		if r < 128 {
+			charset.ascii[r] = true
+		} else {
+			charset.others[r] = true
+		}
+	}
+	return charset
+}
+
+// Name returns the name tags give the charset.
+func (charset *Charset) Name() string {
+	return charset.name
+}
+
+// Allows reports whether the charset has r.
+func (charset *Charset) Allows(r rune) bool {
+	if r >= 0 && r < 128 {
+		return charset.ascii[r]
+	}
+	return charset.others[r]
+}
+
+const (
+	alphanumerics = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	swiftXSymbols = "/-?:().,'+ "
+)
+
+var (
+	charsetsMutex sync.RWMutex
+	charsets      = map[string]*Charset{
+		// The SWIFT X character set of MT messages.
+		"swiftx": NewCharset("swiftx", alphanumerics+swiftXSymbols),
+		// The EPC basic Latin character set SEPA schemes must support.
+		"sepa": NewCharset("sepa", alphanumerics+swiftXSymbols),
+		// The characters Faster Payments accepts in names and references.
+		"fps": NewCharset("fps", alphanumerics+swiftXSymbols+`&"#=!%*<>;{@`),
+	}
+)
+
+// RegisterCharset makes a charset available to the charset rule of the tags
+// compiled afterwards. Charsets cannot be replaced, built-in ones included.
+func RegisterCharset(charset *Charset) error {
+	charsetsMutex.Lock()
+	defer charsetsMutex.Unlock()
+
+	if charset.name == "" {
+		return fmt.Errorf("charset has no name")
+	}
+	if _, exists := charsets[charset.name]; exists {
+		return fmt.Errorf("charset %s is already registered", charset.name)
+	}
+	charsets[charset.name] = charset
+	return nil
+}
+
+// LookupCharset returns the charset registered under name.
+func LookupCharset(name string) (*Charset, bool) {
+	charsetsMutex.RLock()
+	defer charsetsMutex.RUnlock()
+
+	charset, ok := charsets[name]
+	return charset, ok
+}
+
+// MustLookupCharset is LookupCharset for charsets known to be registered, as
+// in generated code. It panics if the charset is not.
+func MustLookupCharset(name string) *Charset {
+	charset, ok := LookupCharset(name)
+	if !ok {
+		panic(fmt.Sprintf("tags: charset %s is not registered", name))
+	}
+	return charset
+}
+
+func charsetNames() []string {
+	charsetsMutex.RLock()
+	defer charsetsMutex.RUnlock()
+
+	names := make([]string, 0, len(charsets))
+	for name := range charsets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DisallowedRune is a character of a value that its charset does not allow.
+// Position counts the runes before it.
+type DisallowedRune struct {
+	Rune       rune
+	Position   int
+	Suggestion string
+}
+
+// Disallowed returns the runes of value the charset does not allow, with the
+// replacement Transliterate would make, or nil when it allows them all.
+func (charset *Charset) Disallowed(value string) []DisallowedRune {
+	var disallowed []DisallowedRune
+	position := 0
+	for _, r := range value {
+		if !charset.Allows(r) {
+			disallowed = append(disallowed, DisallowedRune{Rune: r, Position: position, Suggestion: charset.transliterate(r)})
+		}
+		position++
+	}
+	return disallowed
+}
+
+// Transliterate suggests a value the charset allows: accented Latin letters
+// lose their accents, ligatures and typographic quotes and dashes become their
+// ASCII spelling, and other disallowed characters become a dot, or are dropped
+// when the charset has no dot.
+func (charset *Charset) Transliterate(value string) string {
+	transliterated := make([]byte, 0, len(value))
+	for _, r := range value {
+		if charset.Allows(r) {
+			transliterated = append(transliterated, string(r)...)
+		} else {
+			transliterated = append(transliterated, charset.transliterate(r)...)
+		}
+	}
+	return string(transliterated)
+}
+
+func (charset *Charset) transliterate(r rune) string {
+	if replacement, ok := transliteration(r); ok && charset.allowsAll(replacement) {
+		return replacement
+	}
+	if charset.Allows('.') {
+		return "."
+	}
+	return ""
+}
+
+func (charset *Charset) allowsAll(value string) bool {
+	for _, r := range value {
+		if !charset.Allows(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// latinBases holds the base letter of each rune from U+00C0 to U+017F, _ when
+// it has none or is spelled with several letters in latinSpellings.
+const latinBases = "AAAAAA_CEEEEIIIIDNOOOOO_OUUUUY__aaaaaa_ceeeeiiiidnooooo_ouuuuy_y" +
+	"AaAaAaCcCcCcCcDdDdEeEeEeEeEeGgGgGgGgHhHhIiIiIiIiIi__JjKkkLlLlLlL" +
+	"lLlNnNnNn_NnOoOoOo__RrRrRrSsSsSsSsTtTtTtUuUuUuUuUuUuWwYyYZzZzZzs"
+
+var latinSpellings = map[rune]string{
+	'ß': "ss", 'Æ': "AE", 'æ': "ae", 'Œ': "OE", 'œ': "oe", 'Þ': "TH", 'þ': "th", 'Ĳ': "IJ", 'ĳ': "ij", 'ŉ': "'n",
+	'‘': "'", '’': "'", '‚': "'", '“': `"`, '”': `"`, '„': `"`, '«': `"`, '»': `"`,
+	'\u2010': "-", '\u2011': "-", '\u2012': "-", '–': "-", '—': "-", '\u00a0': " ",
+}
+
+func transliteration(r rune) (string, bool) {
+	if unicode.Is(unicode.Mn, r) {
+		// Combining accents go, leaving the letter they were on.
+		return "", true
+	}
+	if spelling, ok := latinSpellings[r]; ok {
+		return spelling, true
+	}
+	if r >= 0xC0 && r <= 0x17F && latinBases[r-0xC0] != '_' {
+		return latinBases[r-0xC0 : r-0xC0+1], true
+	}
+	return "", false
+}
@@ -0,0 +1,90 @@
+package tags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CharsetDisallowedReportsEachRuneWithItsPosition(t *testing.T) {
+	cases := []struct {
+		description        string
+		charset            string
+		value              string
+		expectedDisallowed []DisallowedRune
+	}{
+		{description: "allowed characters", charset: "swiftx", value: "INV/2026-03 (rent) 1,5+'?:", expectedDisallowed: nil},
+		{description: "empty value", charset: "swiftx", value: "", expectedDisallowed: nil},
+		{
+			description: "accented letters",
+			charset:     "sepa",
+			value:       "Zoë Müller",
+			expectedDisallowed: []DisallowedRune{
+				{Rune: 'ë', Position: 2, Suggestion: "e"},
+				{Rune: 'ü', Position: 5, Suggestion: "u"},
+			},
+		},
+		{
+			description:        "symbols without transliteration",
+			charset:            "swiftx",
+			value:              "A&B",
+			expectedDisallowed: []DisallowedRune{{Rune: '&', Position: 1, Suggestion: "."}},
+		},
+		{description: "FPS allows more symbols", charset: "fps", value: "A&B #1 @home", expectedDisallowed: nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			assert.Equal(t, c.expectedDisallowed, MustLookupCharset(c.charset).Disallowed(c.value))
+		})
+	}
+}
+
+func Test_CharsetTransliterateSuggestsAllowedValues(t *testing.T) {
+	cases := []struct {
+		description string
+		charset     string
+		value       string
+		expected    string
+	}{
+		{description: "allowed value", charset: "sepa", value: "Jose Muller", expected: "Jose Muller"},
+		{description: "precomposed accents", charset: "sepa", value: "José Müller", expected: "Jose Muller"},
+		{description: "combining accents", charset: "sepa", value: "José", expected: "Jose"},
+		{description: "ligatures and special letters", charset: "sepa", value: "Straße Ærø Łódź", expected: "Strasse AEro Lodz"},
+		{description: "typographic quotes and dashes", charset: "swiftx", value: "O’Neill – “rent”", expected: "O'Neill - .rent."},
+		{description: "quotes the charset allows", charset: "fps", value: "“rent”", expected: `"rent"`},
+		{description: "other scripts", charset: "swiftx", value: "王 & 1", expected: ". . 1"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			transliterated := MustLookupCharset(c.charset).Transliterate(c.value)
+
+			assert.Equal(t, c.expected, transliterated)
+			assert.Nil(t, MustLookupCharset(c.charset).Disallowed(transliterated))
+		})
+	}
+}
+
+func Test_RegisterCharset(t *testing.T) {
+	digits := NewCharset("test_digits", "0123456789")
+	t.Cleanup(func() {
+		charsetsMutex.Lock()
+		delete(charsets, digits.Name())
+		charsetsMutex.Unlock()
+	})
+
+	assert.Nil(t, RegisterCharset(digits))
+	assert.EqualError(t, RegisterCharset(NewCharset("test_digits", "01")), "charset test_digits is already registered")
+	assert.EqualError(t, RegisterCharset(NewCharset("swiftx", "01")), "charset swiftx is already registered")
+	assert.EqualError(t, RegisterCharset(NewCharset("", "01")), "charset has no name")
+
+	validationInfos, err := CompileCountriesValidationInfos("[GB:charset(test_digits)]")
+	assert.Nil(t, err)
+	assert.Equal(t, digits, validationInfos["GB"].charset)
+	assert.Equal(t, "1", digits.Transliterate("a-1"))
+}
+
+func Test_MustLookupCharsetPanicsOnUnknownCharsets(t *testing.T) {
+	assert.PanicsWithValue(t, "tags: charset ascii is not registered", func() { MustLookupCharset("ascii") })
+}
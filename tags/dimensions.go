@@ -0,0 +1,141 @@
+package tags
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Dimensions is what a struct is validated for, by dimension name, e.g. its
+// country, payment scheme and currency. Clauses of a tag select the rules to
+// apply on them.
+type Dimensions map[string]string
+
+// Dimensions with a short form in tags, GB/FPS standing for country=GB,scheme=FPS.
+const (
+	CountryDimension = "country"
+	SchemeDimension  = "scheme"
+)
+
+// Key returns the canonical selector of the dimensions, which keys their rules
+// in CountriesValidationInfos: the country and scheme as in GB/FPS, then the
+// other dimensions by name, e.g. GB/FPS,currency=GBP.
+func (dimensions Dimensions) Key() string {
+	_, hasCountry := dimensions[CountryDimension]
+	terms := make([]string, 0, len(dimensions))
+	for _, name := range dimensions.names() {
+		switch {
+		case name == CountryDimension:
+			terms = append(terms, ValidationKey(dimensions[CountryDimension], dimensions[SchemeDimension]))
+		case name == SchemeDimension && hasCountry:
+		default:
+			terms = append(terms, name+string(dimensionValueSeparator)+dimensions[name])
+		}
+	}
+	return strings.Join(terms, string(validationSeparator))
+}
+
+// names returns the names of the dimensions in order of precedence: country,
+// scheme, then the others alphabetically.
+func (dimensions Dimensions) names() []string {
+	names := make([]string, 0, len(dimensions))
+	for name := range dimensions {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return dimensionLess(names[i], names[j]) })
+	return names
+}
+
+func dimensionLess(a, b string) bool {
+	for _, first := range []string{CountryDimension, SchemeDimension} {
+		if a == first || b == first {
+			return a == first && b != first
+		}
+	}
+	return a < b
+}
+
+// describe returns the dimensions as errors mention them, e.g. country is GB and currency is GBP.
+func (dimensions Dimensions) describe() string {
+	_, hasCountry := dimensions[CountryDimension]
+	var terms []string
+	for _, name := range dimensions.names() {
+		switch {
+		case name == CountryDimension:
+			terms = append(terms, "country is "+ValidationKey(dimensions[CountryDimension], dimensions[SchemeDimension]))
+		case name == SchemeDimension && hasCountry:
+		default:
+			terms = append(terms, name+" is "+dimensions[name])
+		}
+	}
+	return strings.Join(terms, " and ")
+}
+
+// keyDimensions parses a key of CountriesValidationInfos back into its dimensions.
+func keyDimensions(key string) Dimensions {
+	dimensions := make(Dimensions)
+	if key == "" {
+		return dimensions
+	}
+	for _, term := range strings.Split(key, string(validationSeparator)) {
+		if i := strings.IndexByte(term, byte(dimensionValueSeparator)); i >= 0 {
+			dimensions[term[:i]] = term[i+1:]
+		} else if i := strings.IndexByte(term, byte(schemeSeparator)); i >= 0 {
+			dimensions[CountryDimension], dimensions[SchemeDimension] = term[:i], term[i+1:]
+		} else {
+			dimensions[CountryDimension] = term
+		}
+	}
+	return dimensions
+}
+
+// selects reports whether every dimension of the selector has the same value in dimensions.
+func (selector Dimensions) selects(dimensions Dimensions) bool {
+	for name, value := range selector {
+		if actual, ok := dimensions[name]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// moreSpecific reports whether the rules of the selector take precedence over
+// those of other when both apply: it selects on more dimensions, or on as many
+// and on the first dimension, in order of precedence, that other does not.
+func (selector Dimensions) moreSpecific(other Dimensions) bool {
+	if len(selector) != len(other) {
+		return len(selector) > len(other)
+	}
+	union := make(Dimensions)
+	for _, dimensions := range []Dimensions{selector, other} {
+		for name := range dimensions {
+			union[name] = ""
+		}
+	}
+	for _, name := range union.names() {
+		_, inSelector := selector[name]
+		_, inOther := other[name]
+		if inSelector != inOther {
+			return inSelector
+		}
+	}
+	return false
+}
+
+// rulesFor returns the rules in effect at asOf of the most specific selector of
+// dimensions with rules at that time, so the rules of GB/FPS apply to FPS
+// payments of GB and those of GB to its other payments.
+func (countriesValidationInfos CountriesValidationInfos) rulesFor(dimensions Dimensions, asOf time.Time) *CountryValidationInfo {
+	var mostSpecific Dimensions
+	var rules *CountryValidationInfo
+	for key, periods := range countriesValidationInfos {
+		selector := keyDimensions(key)
+		if !selector.selects(dimensions) {
+			continue
+		}
+		if countryValidationInfo := periods.at(asOf); countryValidationInfo != nil && (rules == nil || selector.moreSpecific(mostSpecific)) {
+			mostSpecific, rules = selector, countryValidationInfo
+		}
+	}
+	return rules
+}
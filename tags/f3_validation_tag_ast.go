@@ -0,0 +1,71 @@
+package tags
+
+// TagNode is the root of a parsed f3_validate tag:
+// [{clause} | {clause}]
+type TagNode struct {
+	Source  string
+	Clauses []*ClauseNode
+	Span    Span
+}
+
+// ClauseNode applies its rules to every selector its terms stand for:
+// {term},{term}:{rule},{rule}
+type ClauseNode struct {
+	Countries []*CountryNode
+	Rules     []*RuleNode
+	Span      Span
+}
+
+// CountryNode is a selector term of a clause: a country code, optionally
+// narrowed to a payment scheme, e.g. GB or GB/FPS, or the value of another
+// dimension, e.g. currency=GBP. country=GB and scheme=FPS are read as the
+// country and scheme they name.
+type CountryNode struct {
+	Code      string
+	Scheme    string
+	Dimension string
+	Value     string
+	Span      Span
+}
+
+// Dimensions returns the dimensions the term selects on.
+func (country *CountryNode) Dimensions() Dimensions {
+	dimensions := make(Dimensions)
+	if country.Code != "" {
+		dimensions[CountryDimension] = country.Code
+	}
+	if country.Scheme != "" {
+		dimensions[SchemeDimension] = country.Scheme
+	}
+	if country.Dimension != "" {
+		dimensions[country.Dimension] = country.Value
+	}
+	return dimensions
+}
+
+// Key returns the canonical form of the term, e.g. GB/FPS or currency=GBP.
+func (country *CountryNode) Key() string {
+	return country.Dimensions().Key()
+}
+
+// lengthRuleName is the rule produced by length literals such as 7 or 7-10.
+// It cannot be written as a named rule.
+const lengthRuleName = "length"
+
+// RuleNode is a named rule with its arguments, e.g. required or oneof(GBP,EUR).
+// Length literals are parsed into a "length" rule with the min and max as
+// arguments and the unit they are prefixed with, if any, e.g. runes in runes:1-35.
+type RuleNode struct {
+	Name string
+	Unit string
+	Args []*ArgNode
+	Span Span
+}
+
+// ArgNode is a rule argument. Unquoted arguments keep their source text, so
+// 2026-03-01 is a single argument even though it spans several tokens.
+type ArgNode struct {
+	Value  string
+	Quoted bool
+	Span   Span
+}
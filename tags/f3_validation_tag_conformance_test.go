@@ -0,0 +1,151 @@
+package tags
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "rewrite the expected files of the conformance suite and the golden documents")
+
+const conformanceDir = "testdata/conformance"
+
+// conformanceImplementations are run against every case of the conformance
+// suite. An implementation parses and compiles a tag, returning its AST.
+var conformanceImplementations = map[string]func(string) (*TagNode, error){
+	"parser": func(tag string) (*TagNode, error) {
+		node, err := ParseTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := CompileTag(node); err != nil {
+			return nil, err
+		}
+		return node, nil
+	},
+}
+
+type conformanceAST struct {
+	Clauses   []conformanceClause `json:"clauses"`
+	Selectors []string            `json:"selectors"`
+}
+
+type conformanceClause struct {
+	Countries []string          `json:"countries"`
+	Rules     []conformanceRule `json:"rules"`
+}
+
+type conformanceRule struct {
+	Name string   `json:"name"`
+	Unit string   `json:"unit,omitempty"`
+	Args []string `json:"args,omitempty"`
+}
+
+type conformanceError struct {
+	Code     ErrorCode `json:"code"`
+	Position int       `json:"position"`
+}
+
+// toConformanceAST returns the AST of a tag with the keys of the selectors its
+// clauses expand to, sorted.
+func toConformanceAST(t *testing.T, node *TagNode) conformanceAST {
+	var ast conformanceAST
+	compiled, err := CompileTag(node)
+	assert.Nil(t, err)
+	for key := range compiled {
+		ast.Selectors = append(ast.Selectors, key)
+	}
+	sort.Strings(ast.Selectors)
+
+	for _, clause := range node.Clauses {
+		var c conformanceClause
+		for _, country := range clause.Countries {
+			c.Countries = append(c.Countries, country.Key())
+		}
+		for _, rule := range clause.Rules {
+			r := conformanceRule{Name: rule.Name, Unit: rule.Unit}
+			for _, arg := range rule.Args {
+				r.Args = append(r.Args, arg.Value)
+			}
+			c.Rules = append(c.Rules, r)
+		}
+		ast.Clauses = append(ast.Clauses, c)
+	}
+	return ast
+}
+
+// readConformanceCases returns the tags of a suite directory keyed by case name.
+// A case is a NAME.tag file holding the tag, followed by an optional newline,
+// and a NAME.json file holding the expected AST or error.
+func readConformanceCases(t *testing.T, dir string) map[string]string {
+	tagFiles, err := filepath.Glob(filepath.Join(conformanceDir, dir, "*.tag"))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, tagFiles)
+
+	cases := make(map[string]string)
+	for _, tagFile := range tagFiles {
+		content, err := os.ReadFile(tagFile)
+		assert.Nil(t, err)
+		cases[strings.TrimSuffix(tagFile, ".tag")] = strings.TrimSuffix(string(content), "\n")
+	}
+	return cases
+}
+
+func checkConformanceExpectation(t *testing.T, expectedFile string, actual interface{}) {
+	if *update {
+		content, err := json.MarshalIndent(actual, "", "  ")
+		assert.Nil(t, err)
+		assert.Nil(t, os.WriteFile(expectedFile, append(content, '\n'), 0644))
+		return
+	}
+
+	content, err := os.ReadFile(expectedFile)
+	if !assert.Nil(t, err, "missing expected file, run go test -run Conformance -update") {
+		return
+	}
+	actualContent, err := json.Marshal(actual)
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(content), string(actualContent))
+}
+
+func Test_ConformanceValidTags(t *testing.T) {
+	for name, tag := range readConformanceCases(t, "valid") {
+		for implementationName, implementation := range conformanceImplementations {
+			t.Run(filepath.Base(name)+"/"+implementationName, func(t *testing.T) {
+				node, err := implementation(tag)
+				if !assert.Nil(t, err) {
+					return
+				}
+				checkConformanceExpectation(t, name+".json", toConformanceAST(t, node))
+			})
+		}
+	}
+}
+
+func Test_ConformanceInvalidTags(t *testing.T) {
+	for name, tag := range readConformanceCases(t, "invalid") {
+		for implementationName, implementation := range conformanceImplementations {
+			t.Run(filepath.Base(name)+"/"+implementationName, func(t *testing.T) {
+				_, err := implementation(tag)
+				tagError, ok := err.(*TagError)
+				if !assert.True(t, ok, "expected a TagError") {
+					return
+				}
+				checkConformanceExpectation(t, name+".json", conformanceError{Code: tagError.Code, Position: tagError.Position})
+			})
+		}
+	}
+}
+
+func Test_ConformanceGrammarVersionMatchesSpecification(t *testing.T) {
+	grammar, err := os.ReadFile("../docs/f3_validate.ebnf")
+
+	assert.Nil(t, err)
+	assert.Contains(t, string(grammar), "f3_validate struct tag grammar, version "+GrammarVersion+"\n")
+}
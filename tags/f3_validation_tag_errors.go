@@ -0,0 +1,81 @@
+package tags
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrorCode identifies the kind of problem reported by a TagError.
+type ErrorCode string
+
+const (
+	CodeUnexpectedSymbol    ErrorCode = "UNEXPECTED_SYMBOL"
+	CodeUnexpectedToken     ErrorCode = "UNEXPECTED_TOKEN"
+	CodeDuplicateCountry    ErrorCode = "DUPLICATE_COUNTRY"
+	CodeUnterminatedTag     ErrorCode = "UNTERMINATED_TAG"
+	CodeTrailingInput       ErrorCode = "TRAILING_INPUT"
+	CodeInvalidArgument     ErrorCode = "INVALID_ARGUMENT"
+	CodeTooManySelectors    ErrorCode = "TOO_MANY_SELECTORS"
+	CodeConflictingSelector ErrorCode = "CONFLICTING_SELECTOR"
+
+	CodeLengthOutOfRange  ErrorCode = "LENGTH_OUT_OF_RANGE"
+	CodeMinGreaterThanMax ErrorCode = "MIN_GREATER_THAN_MAX"
+	CodeZeroMaxLength     ErrorCode = "ZERO_MAX_LENGTH"
+
+	// Reported when the matrix of a struct is built.
+	CodeUnknownField        ErrorCode = "UNKNOWN_FIELD"
+	CodeIncomparableField   ErrorCode = "INCOMPARABLE_FIELD"
+	CodeUnnormalizableField ErrorCode = "UNNORMALIZABLE_FIELD"
+	CodeUnsupportedField    ErrorCode = "UNSUPPORTED_FIELD"
+
+	// Reported by LintTag on tags that compile.
+	CodeUnknownCountry ErrorCode = "UNKNOWN_COUNTRY"
+	CodeDuplicateRule  ErrorCode = "DUPLICATE_RULE"
+)
+
+// TagError is the diagnostic returned when an f3_validate tag cannot be compiled.
+type TagError struct {
+	Code     ErrorCode
+	Tag      string
+	Position int
+	Reason   string
+	Expected []string
+
+	// Owner of the tag, filled in when the tag is compiled from a struct field.
+	StructType string
+	FieldName  string
+	FieldType  string
+}
+
+// Summary returns the one line description of the error, without the tag excerpt.
+func (e *TagError) Summary() string {
+	summary := fmt.Sprintf("%s in position %d", e.Reason, e.Position)
+	if len(e.Expected) > 0 {
+		summary += ", expected " + joinAlternatives(e.Expected)
+	}
+	return summary
+}
+
+// Excerpt returns the tag with a caret under the offending position.
+func (e *TagError) Excerpt() string {
+	return e.Tag + "\n" + strings.Repeat(" ", e.Position) + "^"
+}
+
+func (e *TagError) Error() string {
+	message := e.Summary()
+	if e.FieldName != "" {
+		owner := e.FieldName
+		if e.StructType != "" {
+			owner = e.StructType + "." + owner
+		}
+		message = fmt.Sprintf("field %s (%s): %s", owner, e.FieldType, message)
+	}
+	return message + "\n\t" + strings.ReplaceAll(e.Excerpt(), "\n", "\n\t")
+}
+
+func joinAlternatives(alternatives []string) string {
+	if len(alternatives) == 1 {
+		return alternatives[0]
+	}
+	return strings.Join(alternatives[:len(alternatives)-1], ", ") + " or " + alternatives[len(alternatives)-1]
+}
@@ -0,0 +1,39 @@
+package tags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TagErrorIncludesExpectedSymbolsAndExcerpt(t *testing.T) {
+	_, err := CompileCountriesValidationInfos("[GB->7-10,required | PT:5]")
+
+	tagError, ok := err.(*TagError)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"'='", "'/'", "','", "':'"}, tagError.Expected)
+	assert.Equal(t, "[GB->7-10,required | PT:5]\n   ^", tagError.Excerpt())
+	assert.Equal(t, "unexpected - symbol in position 3, expected '=', '/', ',' or ':'\n\t[GB->7-10,required | PT:5]\n\t   ^", tagError.Error())
+}
+
+func Test_TagErrorForUnknownTokenListsKnownTokens(t *testing.T) {
+	_, err := CompileCountriesValidationInfos("[GB:7-10,mandatory]")
+
+	assert.Equal(t, "unexpected token mandatory in position 9, expected charset, digitsonly, eqfield, gtfield, lower, ltfield, nefield, nospace, oneof, pattern, required, required_if, required_unless, since, trim, until or upper", err.(*TagError).Summary())
+}
+
+type accountWithWrongBankId struct {
+	Country string
+	BankId  string `f3_validate:"[GB:7+10]"`
+}
+
+func Test_TagErrorIncludesFieldThatOwnsTheTag(t *testing.T) {
+	_, err := CreateValidationMatrix(accountWithWrongBankId{})
+
+	tagError, ok := err.(*TagError)
+	assert.True(t, ok)
+	assert.Equal(t, "tags.accountWithWrongBankId", tagError.StructType)
+	assert.Equal(t, "BankId", tagError.FieldName)
+	assert.Equal(t, "string", tagError.FieldType)
+	assert.Equal(t, "field tags.accountWithWrongBankId.BankId (string): unexpected + symbol in position 5, expected '-', ',', rule, '|' or ']'\n\t[GB:7+10]\n\t     ^", err.Error())
+}
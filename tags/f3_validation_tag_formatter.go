@@ -0,0 +1,239 @@
+package tags
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format parses and compiles a tag and re-emits it in canonical form: countries
+// sorted inside each clause, clauses sorted by their countries, rules in
+// the order of ruleRanks and no spaces other than around the clause separator.
+//
+//	Format(" [ PT:5 | GB:required, 7-10 ]") == "[GB:7-10,required | PT:5]"
+func Format(tag string) (string, error) {
+	node, err := ParseTag(tag)
+	if err != nil {
+		return "", err
+	}
+	if _, err := CompileTag(node); err != nil {
+		return "", err
+	}
+	return FormatTag(node), nil
+}
+
+// FormatTag prints a parsed tag in canonical form without checking its rules.
+func FormatTag(node *TagNode) string {
+	type formattedClause struct{ selectors, rules string }
+	clauses := make([]formattedClause, 0, len(node.Clauses))
+	for _, clause := range node.Clauses {
+		clauses = append(clauses, formattedClause{formatSelectors(clause), formatRules(clause)})
+	}
+	sort.Slice(clauses, func(i, j int) bool {
+		if clauses[i].selectors != clauses[j].selectors {
+			return clauses[i].selectors < clauses[j].selectors
+		}
+		return clauses[i].rules < clauses[j].rules
+	})
+
+	formatted := make([]string, 0, len(clauses))
+	for _, clause := range clauses {
+		formatted = append(formatted, clause.selectors+string(countryValidationInitializer)+clause.rules)
+	}
+	return string(validationOpener) + strings.Join(formatted, " "+string(countrySeparator)+" ") + string(validationCloser)
+}
+
+func formatSelectors(clause *ClauseNode) string {
+	countries := make([]string, 0, len(clause.Countries))
+	for _, country := range clause.Countries {
+		countries = append(countries, country.Key())
+	}
+	sort.Strings(countries)
+	return strings.Join(countries, string(validationSeparator))
+}
+
+func formatRules(clause *ClauseNode) string {
+
+	rules := make([]*RuleNode, len(clause.Rules))
+	copy(rules, clause.Rules)
+	sort.SliceStable(rules, func(i, j int) bool {
+		rankI, rankJ := ruleRank(rules[i]), ruleRank(rules[j])
+		if rankI != rankJ {
+			return rankI < rankJ
+		}
+		return rankI == unrankedRule && rules[i].Name < rules[j].Name
+	})
+
+	formattedRules := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		formattedRules = append(formattedRules, formatRule(rule))
+	}
+
+	return strings.Join(formattedRules, string(validationSeparator))
+}
+
+// ruleRanks give the canonical order of the rules of a clause, in which both
+// FormatTag and String print them: normalizers, lengths, oneof, pattern,
+// charset, the required rules, comparisons, since and until. Normalizers and
+// comparisons keep their order, which is the one they apply in. Rules without
+// a rank, which FormatTag prints without checking, come last, sorted by name.
+var ruleRanks = map[string]int{
+	lengthRuleName:    1,
+	"oneof":           2,
+	"pattern":         3,
+	"charset":         4,
+	"required":        5,
+	"required_if":     6,
+	"required_unless": 7,
+	"eqfield":         8,
+	"nefield":         8,
+	"gtfield":         8,
+	"ltfield":         8,
+	"since":           9,
+	"until":           10,
+}
+
+const unrankedRule = 11
+
+func ruleRank(rule *RuleNode) int {
+	if _, normalizes := normalizers[rule.Name]; normalizes {
+		return 0
+	}
+	if rank, ok := ruleRanks[rule.Name]; ok {
+		return rank
+	}
+	return unrankedRule
+}
+
+func formatRule(rule *RuleNode) string {
+	if rule.Name == lengthRuleName && (len(rule.Args) == 1 || len(rule.Args) == 2) && isDecimal(rule.Args[0].Value) && isDecimal(rule.Args[len(rule.Args)-1].Value) {
+		// The digits are kept as written, as a length may not fit an int.
+		return formatLengthLiterals(rule.Unit, trimLeadingZeros(rule.Args[0].Value), trimLeadingZeros(rule.Args[len(rule.Args)-1].Value))
+	}
+	if len(rule.Args) == 0 {
+		return rule.Name
+	}
+
+	args := make([]string, 0, len(rule.Args))
+	for _, arg := range rule.Args {
+		args = append(args, formatArg(arg.Value))
+	}
+	return rule.Name + string(argumentsOpener) + strings.Join(args, string(validationSeparator)) + string(argumentsCloser)
+}
+
+func formatLength(unit LengthUnit, minLen, maxLen int) string {
+	return formatLengthLiterals(string(unit), strconv.Itoa(minLen), strconv.Itoa(maxLen))
+}
+
+func formatLengthLiterals(unit, minLen, maxLen string) string {
+	length := minLen
+	if minLen != maxLen {
+		length += string(numericLengthSeparator) + maxLen
+	}
+	if unit != "" {
+		length = unit + string(countryValidationInitializer) + length
+	}
+	return length
+}
+
+func trimLeadingZeros(number string) string {
+	if trimmed := strings.TrimLeft(number, "0"); trimmed != "" {
+		return trimmed
+	}
+	return "0"
+}
+
+// formatArg leaves arguments that lex back into the same raw argument unquoted
+// and quotes everything else.
+func formatArg(value string) string {
+	// An underscore only lexes within an identifier, which starts with a letter.
+	raw, identifier := value != "", false
+	for i := 0; i < len(value) && raw; i++ {
+		c := value[i]
+		switch {
+		case IsLetter(c):
+			identifier = true
+		case c == '_':
+			raw = identifier
+		case IsNumeric(c):
+		case Symbol(c) == numericLengthSeparator || Symbol(c) == countryValidationInitializer:
+			identifier = false
+		default:
+			raw = false
+		}
+	}
+	if raw {
+		return value
+	}
+
+	quoted := strings.NewReplacer(`\`, `\\`, string(stringDelimiter), `\`+string(stringDelimiter)).Replace(value)
+	return string(stringDelimiter) + quoted + string(stringDelimiter)
+}
+
+// String returns the rules of the country in canonical tag form, e.g.
+// "7-10,required", leaving out those of its other periods. Rules come in the
+// order of ruleRanks.
+func (countryValidationInfo *CountryValidationInfo) String() string {
+	rules := append([]string(nil), countryValidationInfo.normalizers...)
+	if countryValidationInfo.minLen > 0 || countryValidationInfo.maxLen > 0 {
+		rules = append(rules, formatLength(countryValidationInfo.lengthUnit, countryValidationInfo.minLen, countryValidationInfo.maxLen))
+	}
+	if len(countryValidationInfo.oneOf) > 0 {
+		values := make([]string, 0, len(countryValidationInfo.oneOf))
+		for _, value := range countryValidationInfo.oneOf {
+			values = append(values, formatArg(value))
+		}
+		rules = append(rules, "oneof"+string(argumentsOpener)+strings.Join(values, string(validationSeparator))+string(argumentsCloser))
+	}
+	if countryValidationInfo.pattern != nil {
+		rules = append(rules, "pattern"+string(argumentsOpener)+formatArg(countryValidationInfo.pattern.String())+string(argumentsCloser))
+	}
+	if countryValidationInfo.charset != nil {
+		rules = append(rules, "charset"+string(argumentsOpener)+formatArg(countryValidationInfo.charset.Name())+string(argumentsCloser))
+	}
+	if countryValidationInfo.required {
+		rules = append(rules, "required")
+	}
+	for _, reference := range []*fieldReference{countryValidationInfo.requiredIf, countryValidationInfo.requiredUnless} {
+		if reference != nil {
+			rules = append(rules, reference.rule+string(argumentsOpener)+formatArg(reference.name)+string(argumentsCloser))
+		}
+	}
+	for _, reference := range countryValidationInfo.comparisons {
+		rules = append(rules, reference.rule+string(argumentsOpener)+formatArg(reference.name)+string(argumentsCloser))
+	}
+	if !countryValidationInfo.since.IsZero() {
+		rules = append(rules, "since"+string(argumentsOpener)+formatArg(formatDate(countryValidationInfo.since))+string(argumentsCloser))
+	}
+	if !countryValidationInfo.until.IsZero() {
+		rules = append(rules, "until"+string(argumentsOpener)+formatArg(formatDate(countryValidationInfo.until))+string(argumentsCloser))
+	}
+	return strings.Join(rules, string(validationSeparator))
+}
+
+// formatDate prints midnight UTC as a date and any other time in RFC 3339.
+func formatDate(date time.Time) string {
+	if date.Equal(date.Truncate(24*time.Hour)) && date.Location() == time.UTC {
+		return date.Format(dateLayouts[0])
+	}
+	return date.Format(time.RFC3339)
+}
+
+// String returns the compiled rules as a canonical tag, one clause per country
+// and period.
+func (countriesValidationInfos CountriesValidationInfos) String() string {
+	countries := make([]string, 0, len(countriesValidationInfos))
+	for country := range countriesValidationInfos {
+		countries = append(countries, country)
+	}
+	sort.Strings(countries)
+
+	clauses := make([]string, 0, len(countries))
+	for _, country := range countries {
+		for period := countriesValidationInfos[country]; period != nil; period = period.next {
+			clauses = append(clauses, country+string(countryValidationInitializer)+period.String())
+		}
+	}
+	return string(validationOpener) + strings.Join(clauses, " "+string(countrySeparator)+" ") + string(validationCloser)
+}
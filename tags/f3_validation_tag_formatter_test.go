@@ -0,0 +1,134 @@
+package tags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FormatReturnsCanonicalTag(t *testing.T) {
+	cases := []struct {
+		description    string
+		tag            string
+		expectedResult string
+	}{
+		{description: "already canonical", tag: "[GB:7-10,required | PT:5]", expectedResult: "[GB:7-10,required | PT:5]"},
+		{description: "spacing is normalised", tag: " [ GB:10-765, required ]  ", expectedResult: "[GB:10-765,required]"},
+		{description: "clauses are sorted", tag: "[PT:5 | GB:7-10,required | AU:10-12, required]", expectedResult: "[AU:10-12,required | GB:7-10,required | PT:5]"},
+		{description: "countries are sorted", tag: "[PT,GB,IE:5]", expectedResult: "[GB,IE,PT:5]"},
+		{description: "rules are sorted", tag: "[GB:required, 7-10]", expectedResult: "[GB:7-10,required]"},
+		{description: "equal bounds collapse", tag: "[GB:7-7]", expectedResult: "[GB:7]"},
+		{description: "schemes are kept", tag: "[GB:6-8 | PT, GB/FPS:6]", expectedResult: "[GB:6-8 | GB/FPS,PT:6]"},
+		{description: "normalizers come first in their order", tag: "[GB:required,upper,15-34,lower]", expectedResult: "[GB:upper,lower,15-34,required]"},
+		{description: "comparisons follow required rules in their order", tag: "[GB:nefield(Name),required,eqfield(IBAN)]", expectedResult: "[GB:required,nefield(Name),eqfield(IBAN)]"},
+		{description: "lengths come before charsets", tag: "[GB:charset(fps),7]", expectedResult: "[GB:7,charset(fps)]"},
+		{description: "dates come last", tag: "[GB:until(2026-03-01),gtfield(StartDate)]", expectedResult: "[GB:gtfield(StartDate),until(2026-03-01)]"},
+		{description: "underscores are quoted outside identifiers", tag: "[GB:pattern('_'),oneof('1_', A_1)]", expectedResult: "[GB:oneof('1_',A_1),pattern('_')]"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			result, err := Format(c.tag)
+
+			assert.Nil(t, err)
+			assert.Equal(t, c.expectedResult, result)
+		})
+	}
+}
+
+func Test_FormatReturnsCompileErrors(t *testing.T) {
+	_, err := Format("[GB:7 | GB:8]")
+
+	assert.Equal(t, CodeDuplicateCountry, err.(*TagError).Code)
+}
+
+func Test_FormatTagQuotesArgumentsOnlyWhenNeeded(t *testing.T) {
+	node, err := ParseTag(`[GB:oneof('GBP', 'a b', 'it\'s', ''), since(2026-03-01)]`)
+
+	assert.Nil(t, err)
+	assert.Equal(t, `[GB:oneof(GBP,'a b','it\'s',''),since(2026-03-01)]`, FormatTag(node))
+}
+
+func Test_FormatTagKeepsLengthsThatDoNotCompile(t *testing.T) {
+	node, err := ParseTag("[GB:007-99999999999999999999 | PT:0]")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "[GB:7-99999999999999999999 | PT:0]", FormatTag(node))
+}
+
+// roundTripTags also seed FuzzCompileCountriesValidationInfos.
+var roundTripTags = []string{
+	"[GB:7-10,required | PT:5]",
+	"[AU:10-12,required | GB:required | PT:5]",
+	"[GB,IE:1-35]",
+	"[GB:runes:1-35,required | IE:graphemes:10 | PT:bytes:5-8]",
+	"[GB:charset(fps) | IE,PT:1-35,charset(sepa)]",
+	"[GB:nospace,upper,15-34 | IE:trim,digitsonly,lower,6,required]",
+	"[GB:required_if(BankIdCode) | IE:nospace,required_unless(AccountNumber)]",
+	"[GB:nefield(Name),gtfield(StartDate),eqfield(IBAN) | IE:ltfield(EndDate)]",
+	`[GB:oneof(GBP,'a b'),pattern('^[A-Z]{2}$'),required]`,
+	"[GB:1-18,until(2026-03-01) | GB:1-35,since(2026-03-01) | PT:since('2026-03-01T12:00:00+01:00')]",
+}
+
+func Test_CompiledRulesRoundTripThroughString(t *testing.T) {
+	for _, tag := range roundTripTags {
+		t.Run(tag, func(t *testing.T) {
+			compiled, err := CompileCountriesValidationInfos(tag)
+			assert.Nil(t, err)
+
+			recompiled, err := CompileCountriesValidationInfos(compiled.String())
+			assert.Nil(t, err)
+			assert.Equal(t, withoutLocations(compiled), withoutLocations(recompiled))
+		})
+	}
+}
+
+// withoutLocations returns the rules without the places of their tag they were
+// compiled from, which printing them moves.
+func withoutLocations(countriesValidationInfos CountriesValidationInfos) CountriesValidationInfos {
+	located := func(location *ruleLocation) *ruleLocation {
+		if location == nil {
+			return nil
+		}
+		return &ruleLocation{rule: location.rule}
+	}
+	stripped := make(CountriesValidationInfos)
+	for key, periods := range countriesValidationInfos {
+		var copies []*CountryValidationInfo
+		for period := periods; period != nil; period = period.next {
+			copied := *period
+			copied.normalizedAt, copied.checkedAt = located(period.normalizedAt), located(period.checkedAt)
+			copies = append(copies, &copied)
+		}
+		for i := range copies {
+			copies[i].next = nil
+			if i+1 < len(copies) {
+				copies[i].next = copies[i+1]
+			}
+		}
+		stripped[key] = copies[0]
+	}
+	return stripped
+}
+
+func Test_FormattedTagsCompileToTheSameRules(t *testing.T) {
+	for _, tag := range append(roundTripTags, "[GB:upper,lower]", "[GB:gtfield(StartDate),required,ltfield(EndDate),trim]") {
+		t.Run(tag, func(t *testing.T) {
+			compiled, err := CompileCountriesValidationInfos(tag)
+			assert.Nil(t, err)
+
+			formatted, err := Format(tag)
+			assert.Nil(t, err)
+			recompiled, err := CompileCountriesValidationInfos(formatted)
+			assert.Nil(t, err)
+			assert.Equal(t, compiled.String(), recompiled.String())
+		})
+	}
+}
+
+func Test_CountryValidationInfoString(t *testing.T) {
+	assert.Equal(t, "7-10,required", (&CountryValidationInfo{minLen: 7, maxLen: 10, required: true}).String())
+	assert.Equal(t, "5", (&CountryValidationInfo{minLen: 5, maxLen: 5}).String())
+	assert.Equal(t, "trim,upper,8-11", (&CountryValidationInfo{minLen: 8, maxLen: 11, normalizers: []string{"trim", "upper"}}).String())
+	assert.Equal(t, "[GB:required | PT:5]", CountriesValidationInfos{"PT": {minLen: 5, maxLen: 5}, "GB": {required: true}}.String())
+}
@@ -2,16 +2,48 @@ package tags
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
+	"time"
 )
 
 type CountryValidationInfo struct {
-	minLen   int
-	maxLen   int
-	required bool
+	minLen      int
+	maxLen      int
+	lengthUnit  LengthUnit // empty for the unit of the validator
+	required    bool
+	pattern     *regexp.Regexp
+	oneOf       []string
+	charset     *Charset
+	normalizers []string // rewrite the value, in order, before it is checked
+
+	// normalizedAt locates the first normalizer and checkedAt the first rule
+	// checking the value otherwise than by comparison, which only string
+	// fields take.
+	normalizedAt *ruleLocation
+	checkedAt    *ruleLocation
+
+	// requiredIf and requiredUnless make the value required when a sibling
+	// field is set, or is not.
+	requiredIf     *fieldReference
+	requiredUnless *fieldReference
+
+	// comparisons compare the value with sibling fields, e.g. gtfield(StartDate).
+	comparisons []*fieldReference
+
+	// since and until bound the period the rules are in effect, since included
+	// and until excluded, zero meaning unbounded. The rules of the other periods
+	// of the country follow in next, ordered by since.
+	since time.Time
+	until time.Time
+	next  *CountryValidationInfo
 }
 
-//Symbols
+// CountriesValidationInfos is the compiled form of a tag, keyed by country code.
+type CountriesValidationInfos map[string]*CountryValidationInfo
+
+// Symbols
 type Symbol byte
 
 const (
@@ -22,195 +54,419 @@ const (
 	countrySeparator       Symbol = '|'
 	numericLengthSeparator Symbol = '-'
 	validationSeparator    Symbol = ','
+	schemeSeparator        Symbol = '/'
+
+	dimensionValueSeparator Symbol = '='
+
+	argumentsOpener Symbol = '('
+	argumentsCloser Symbol = ')'
+	stringDelimiter Symbol = '\''
 )
 
-// Tokens
+// Rules
 
-var tokenMap = map[string]func(*CountryValidationInfo){
-	"required": func(countryValidationInfo *CountryValidationInfo) {
+// RuleCompiler gives meaning to a parsed rule by applying it to the validation
+// info of each country of its clause.
+type RuleCompiler func(*CountryValidationInfo, *RuleNode) error
+
+var ruleCompilers = map[string]RuleCompiler{
+	"required": func(countryValidationInfo *CountryValidationInfo, rule *RuleNode) error {
+		if err := expectArgs(rule, 0, 0); err != nil {
+			return err
+		}
 		countryValidationInfo.required = true
+		return nil
+	},
+	"pattern": func(countryValidationInfo *CountryValidationInfo, rule *RuleNode) error {
+		if err := expectArgs(rule, 1, 1); err != nil {
+			return err
+		}
+		pattern, err := regexp.Compile(rule.Args[0].Value)
+		if err != nil {
+			return &TagError{Code: CodeInvalidArgument, Reason: fmt.Sprintf("rule pattern expects a regular expression but found %s", rule.Args[0].Value), Position: rule.Args[0].Span.Start}
+		}
+		countryValidationInfo.pattern = pattern
+		return nil
+	},
+	"oneof": func(countryValidationInfo *CountryValidationInfo, rule *RuleNode) error {
+		if len(rule.Args) == 0 {
+			return &TagError{Code: CodeInvalidArgument, Reason: "rule oneof takes at least 1 argument but found 0", Position: rule.Span.Start}
+		}
+		countryValidationInfo.oneOf = nil
+		for _, arg := range rule.Args {
+			countryValidationInfo.oneOf = append(countryValidationInfo.oneOf, arg.Value)
+		}
+		return nil
+	},
+	"charset": func(countryValidationInfo *CountryValidationInfo, rule *RuleNode) error {
+		if err := expectArgs(rule, 1, 1); err != nil {
+			return err
+		}
+		charset, ok := LookupCharset(rule.Args[0].Value)
+		if !ok {
+			return &TagError{Code: CodeInvalidArgument, Reason: fmt.Sprintf("unknown charset %s", rule.Args[0].Value), Expected: charsetNames(), Position: rule.Args[0].Span.Start}
+		}
+		countryValidationInfo.charset = charset
+		return nil
+	},
+	"required_if": func(countryValidationInfo *CountryValidationInfo, rule *RuleNode) error {
+		reference, err := compileFieldReference(rule)
+		countryValidationInfo.requiredIf = reference
+		return err
+	},
+	"required_unless": func(countryValidationInfo *CountryValidationInfo, rule *RuleNode) error {
+		reference, err := compileFieldReference(rule)
+		countryValidationInfo.requiredUnless = reference
+		return err
+	},
+	"eqfield":    compileFieldComparison,
+	"nefield":    compileFieldComparison,
+	"gtfield":    compileFieldComparison,
+	"ltfield":    compileFieldComparison,
+	"trim":       compileNormalizer,
+	"upper":      compileNormalizer,
+	"lower":      compileNormalizer,
+	"nospace":    compileNormalizer,
+	"digitsonly": compileNormalizer,
+	"since": func(countryValidationInfo *CountryValidationInfo, rule *RuleNode) error {
+		since, err := expectDateArg(rule)
+		if err != nil {
+			return err
+		}
+		countryValidationInfo.since = since
+		return expectPeriod(countryValidationInfo, rule)
+	},
+	"until": func(countryValidationInfo *CountryValidationInfo, rule *RuleNode) error {
+		until, err := expectDateArg(rule)
+		if err != nil {
+			return err
+		}
+		countryValidationInfo.until = until
+		return expectPeriod(countryValidationInfo, rule)
 	},
 }
 
-// States
-type State string
+// periodRules set the period of the other rules of their clause instead of
+// checking the value.
+var periodRules = map[string]bool{"since": true, "until": true}
 
-const (
-	invalidState                             State = "[INVALID]"
-	initialState                             State = "[INITIAL]"
-	assemblingCountryCode                    State = "[ASSB_COUNTRY_CODE]"
-	assemblingCountryValidation              State = "[ASSB_COUNTRY_VALIDATION]"
-	assemblingContryValidationFieldSize      State = "[ASSB_COUNTRY_VALIDATION_FLD_SIZE]"
-	assemblingContryValidationFieldSizeMax   State = "[ASSB_COUNTRY_VALIDATION_FLD_SIZE_MAX]"
-	assemblingCountryValidationToken         State = "[ASSB_COUNTRY_VALIDATION_FLD_TOKEN]"
-	expectingCountryValidationCloseStatement State = "[ASSB_COUNTRY_VALIDATION_EXPECTING_CLOSE_STATEMENT]"
-	finalState                               State = "[FINAL]"
-)
+// compileLength compiles length literals. length is not the name of a rule, so
+// it cannot be written as one.
+func compileLength(countryValidationInfo *CountryValidationInfo, rule *RuleNode) error {
+	minLen, maxLen, err := expectLengthArgs(rule)
+	if err != nil {
+		return err
+	}
+	if rule.Unit != "" && !isLengthUnit(rule.Unit) {
+		return &TagError{Code: CodeInvalidArgument, Reason: fmt.Sprintf("unknown length unit %s", rule.Unit), Expected: lengthUnitNames(), Position: rule.Span.Start}
+	}
+	countryValidationInfo.minLen = minLen
+	countryValidationInfo.maxLen = maxLen
+	countryValidationInfo.lengthUnit = LengthUnit(rule.Unit)
+	return nil
+}
 
-type TransitionFunction func(byte, *map[string]*CountryValidationInfo, *string, *string, int) (State, error)
+func CompileCountriesValidationInfos(validationStr string) (CountriesValidationInfos, error) {
+	tag, err := ParseTag(validationStr)
+	if err != nil {
+		return nil, err
+	}
+	return CompileTag(tag)
+}
 
-var transitionTable = map[State]TransitionFunction{
+// CompileTag is the semantic pass over a parsed tag: it checks that selectors are
+// defined once for any date and turns each rule into validation info through
+// ruleCompilers.
+func CompileTag(tag *TagNode) (CountriesValidationInfos, error) {
+	countriesValidationInfos := make(CountriesValidationInfos)
 
-	initialState: func(entrySymbol byte, countries *map[string]*CountryValidationInfo, currentCountry *string, accumulator *string, position int) (State, error) {
-		if entrySymbol == byte(validationOpener) {
-			return assemblingCountryCode, nil
-		} else if entrySymbol == ' ' {
-			return initialState, nil
+	for _, clause := range tag.Clauses {
+		selectors, err := clauseSelectors(clause)
+		if err != nil {
+			err.(*TagError).Tag = tag.Source
+			return nil, err
 		}
-		return invalidState, createUnexpectedSymbolError(entrySymbol, position)
-	},
-	assemblingCountryCode: func(entrySymbol byte, countries *map[string]*CountryValidationInfo, accumulator *string, currentCountry *string, position int) (State, error) {
-		if IsLetter(entrySymbol) {
-			*currentCountry += string(entrySymbol)
-
-			return assemblingCountryCode, nil
-		} else if entrySymbol == ' ' && *currentCountry == "" {
-			return assemblingCountryCode, nil
-		} else if entrySymbol == byte(countryValidationInitializer) {
-			if _, alreadyContaisCountry := (*countries)[*currentCountry]; alreadyContaisCountry {
-				return invalidState, fmt.Errorf("country defined twice")
+
+		for _, selector := range selectors {
+			countryValidationInfo := &CountryValidationInfo{}
+
+			for _, rule := range clause.Rules {
+				ruleCompiler := ruleCompilers[rule.Name]
+				if rule.Name == lengthRuleName {
+					ruleCompiler = compileLength
+				}
+				if ruleCompiler == nil {
+					return nil, createUnexpectedTokenError(tag.Source, rule.Name, rule.Span.Start)
+				}
+				if err := ruleCompiler(countryValidationInfo, rule); err != nil {
+					err.(*TagError).Tag = tag.Source
+					return nil, err
+				}
+				if _, compares := fieldComparisons[rule.Name]; !compares && !periodRules[rule.Name] && countryValidationInfo.checkedAt == nil {
+					countryValidationInfo.checkedAt = &ruleLocation{rule: rule.Name, tag: tag.Source, position: rule.Span.Start}
+				}
+			}
+			for _, reference := range countryValidationInfo.fieldReferences() {
+				reference.tag = tag.Source
+			}
+			if countryValidationInfo.normalizedAt != nil {
+				countryValidationInfo.normalizedAt.tag = tag.Source
 			}
 
-			(*countries)[*currentCountry] = &CountryValidationInfo{}
-			return assemblingCountryValidation, nil
+			key := selector.dimensions.Key()
+			periods := countriesValidationInfos[key]
+			if overlapping := periods.overlapping(countryValidationInfo); overlapping != nil {
+				reason := duplicateSelectorReason(selector.dimensions)
+				if overlapping.isDated() || countryValidationInfo.isDated() {
+					reason += " for overlapping dates"
+				}
+				return nil, &TagError{Code: CodeDuplicateCountry, Tag: tag.Source, Reason: reason, Position: selector.term.Span.Start}
+			}
+			countriesValidationInfos[key] = periods.add(countryValidationInfo)
 		}
-		return invalidState, createUnexpectedSymbolError(entrySymbol, position)
-	},
-	assemblingCountryValidation: func(entrySymbol byte, countries *map[string]*CountryValidationInfo, accumulator *string, currentCountry *string, position int) (State, error) {
-		if IsLetter(entrySymbol) {
-			*accumulator += string(entrySymbol)
-			return assemblingCountryValidationToken, nil
-		} else if IsNumeric(entrySymbol) {
-			*accumulator += string(entrySymbol)
-			return assemblingContryValidationFieldSize, nil
-		} else if entrySymbol == ' ' {
-			return assemblingCountryValidation, nil
-		}
-		return invalidState, createUnexpectedSymbolError(entrySymbol, position)
-	},
-	expectingCountryValidationCloseStatement: func(entrySymbol byte, countries *map[string]*CountryValidationInfo, accumulator, currentCountry *string, position int) (State, error) {
-		if entrySymbol == byte(validationCloser) {
-			return finalState, nil
-		} else if entrySymbol == byte(validationSeparator) {
-			return assemblingCountryValidation, nil
-		} else if entrySymbol == ' ' {
-			return expectingCountryValidationCloseStatement, nil
-		} else if IsLetter(entrySymbol) {
-			*accumulator += string(entrySymbol)
-			return assemblingCountryValidationToken, nil
-		} else if entrySymbol == byte(countrySeparator) {
-			*currentCountry = ""
-			return assemblingCountryCode, nil
-		}
-		return invalidState, createUnexpectedSymbolError(entrySymbol, position)
-	},
-	assemblingContryValidationFieldSize: func(entrySymbol byte, countries *map[string]*CountryValidationInfo, accumulator *string, currentCountry *string, position int) (State, error) {
-		if IsNumeric(entrySymbol) {
-			*accumulator += string(entrySymbol)
-			return assemblingContryValidationFieldSize, nil
-		} else if entrySymbol == byte(numericLengthSeparator) {
-			(*countries)[*currentCountry].minLen = ParseToInt(*accumulator)
-			*accumulator = ""
-			return assemblingContryValidationFieldSizeMax, nil
-		} else if entrySymbol == byte(validationCloser) {
-			(*countries)[*currentCountry].maxLen = ParseToInt(*accumulator)
-			(*countries)[*currentCountry].minLen = ParseToInt(*accumulator)
-			*accumulator = ""
-			return finalState, nil
-		} else if entrySymbol == byte(validationSeparator) {
-			(*countries)[*currentCountry].maxLen = ParseToInt(*accumulator)
-			(*countries)[*currentCountry].minLen = ParseToInt(*accumulator)
-			*accumulator = ""
-			return assemblingCountryValidation, nil
-		} else if entrySymbol == ' ' {
-			(*countries)[*currentCountry].maxLen = ParseToInt(*accumulator)
-			(*countries)[*currentCountry].minLen = ParseToInt(*accumulator)
-			*accumulator = ""
-			return expectingCountryValidationCloseStatement, nil
-		}
-		return invalidState, createUnexpectedSymbolError(entrySymbol, position)
-	},
-	assemblingContryValidationFieldSizeMax: func(entrySymbol byte, countries *map[string]*CountryValidationInfo, accumulator *string, currentCountry *string, position int) (State, error) {
-		if IsNumeric(entrySymbol) {
-			*accumulator += string(entrySymbol)
-			return assemblingContryValidationFieldSizeMax, nil
-		} else if entrySymbol == byte(validationSeparator) {
-			(*countries)[*currentCountry].maxLen = ParseToInt(*accumulator)
-			*accumulator = ""
-			return assemblingCountryValidation, nil
-		} else if entrySymbol == byte(validationCloser) {
-			(*countries)[*currentCountry].maxLen = ParseToInt(*accumulator)
-			*accumulator = ""
-			return finalState, nil
-		} else if entrySymbol == ' ' {
-			(*countries)[*currentCountry].maxLen = ParseToInt(*accumulator)
-			*accumulator = ""
-			return expectingCountryValidationCloseStatement, nil
-		}
-		return invalidState, createUnexpectedSymbolError(entrySymbol, position)
-	},
-	assemblingCountryValidationToken: func(entrySymbol byte, countries *map[string]*CountryValidationInfo, accumulator *string, currentCountry *string, position int) (State, error) {
-		if IsLetter(entrySymbol) {
-			*accumulator += string(entrySymbol)
-			return assemblingCountryValidationToken, nil
-		} else if entrySymbol == ' ' || entrySymbol == byte(validationCloser) {
-			tokenFunction := tokenMap[*accumulator]
-			if tokenFunction == nil {
-				return invalidState, createUnexpectedTokenError(*accumulator, position)
+	}
+
+	return countriesValidationInfos, nil
+}
+
+// clauseSelector is a selector a clause applies its rules to, with the term it
+// is reported at.
+type clauseSelector struct {
+	dimensions Dimensions
+	term       *CountryNode
+}
+
+// maxClauseSelectors bounds the selectors a clause expands to, as each
+// dimension with several values multiplies them.
+const maxClauseSelectors = 1024
+
+// clauseSelectors expands the terms of a clause into the selectors they stand
+// for. Terms naming a country, e.g. GB or GB/FPS, are alternatives, as are
+// terms of the same other dimension, and the alternatives of each narrow the
+// others: GB,IE/FPS stands for GB and IE/FPS, and GB,IE/FPS,currency=GBP for
+// GB,currency=GBP and IE/FPS,currency=GBP.
+func clauseSelectors(clause *ClauseNode) ([]clauseSelector, error) {
+	var groups []string
+	alternatives := make(map[string][]*CountryNode)
+	terms := make(map[string]bool)
+	for _, country := range clause.Countries {
+		dimensions := country.Dimensions()
+		if terms[country.Key()] {
+			return nil, &TagError{Code: CodeDuplicateCountry, Reason: duplicateSelectorReason(dimensions), Position: country.Span.Start}
+		}
+		terms[country.Key()] = true
+
+		group := CountryDimension
+		if country.Code == "" {
+			group = dimensions.names()[0]
+		}
+		if alternatives[group] == nil {
+			groups = append(groups, group)
+		}
+		alternatives[group] = append(alternatives[group], country)
+	}
+
+	count := 1
+	for _, group := range groups {
+		if count *= len(alternatives[group]); count > maxClauseSelectors {
+			return nil, &TagError{Code: CodeTooManySelectors, Reason: fmt.Sprintf("clause selects more than %d combinations of dimensions", maxClauseSelectors), Position: clause.Span.Start}
+		}
+	}
+
+	products := []Dimensions{{}}
+	for _, group := range groups {
+		var next []Dimensions
+		for _, product := range products {
+			for _, term := range alternatives[group] {
+				dimensions := Dimensions{}
+				for productName, productValue := range product {
+					dimensions[productName] = productValue
+				}
+				for name, value := range term.Dimensions() {
+					if _, selected := dimensions[name]; selected {
+						return nil, &TagError{Code: CodeConflictingSelector, Reason: fmt.Sprintf("%s cannot narrow %s, which already selects a %s", term.Key(), product.Key(), name), Position: term.Span.Start}
+					}
+					dimensions[name] = value
+				}
+				next = append(next, dimensions)
 			}
-			tokenFunction((*countries)[*currentCountry])
-			*accumulator = ""
+		}
+		products = next
+	}
 
-			if entrySymbol == ' ' {
-				return expectingCountryValidationCloseStatement, nil
+	selectors := make([]clauseSelector, 0, len(products))
+	for _, dimensions := range products {
+		selector := clauseSelector{dimensions: dimensions}
+		for _, country := range clause.Countries {
+			if country.Dimensions().selects(dimensions) && (selector.term == nil || country.Dimensions().moreSpecific(selector.term.Dimensions())) {
+				selector.term = country
 			}
-			return finalState, nil
 		}
-		return assemblingCountryValidationToken, nil
-	},
+		selectors = append(selectors, selector)
+	}
+	return selectors, nil
 }
 
-func CompileCountriesValidationInfos(validationStr string) (map[string]*CountryValidationInfo, error) {
-	currentState := initialState
-	var currentSymbol byte
-	currentCountry := new(string)
-	accumulator := new(string)
-	var stateError error = nil
-	countriesValidationInfos := make(map[string]*CountryValidationInfo)
+// duplicateSelectorReason tells of a country, or of another selector, defined twice.
+func duplicateSelectorReason(dimensions Dimensions) string {
+	kind := "country"
+	for name := range dimensions {
+		if name != CountryDimension && name != SchemeDimension {
+			kind = "selector"
+		}
+	}
+	if _, hasCountry := dimensions[CountryDimension]; !hasCountry {
+		kind = "selector"
+	}
+	return fmt.Sprintf("%s %s defined twice", kind, dimensions.Key())
+}
 
-	for i := 0; i < len(validationStr); i++ {
-		currentSymbol = validationStr[i]
-		currentState, stateError = transitionTable[currentState](currentSymbol, &countriesValidationInfos, accumulator, currentCountry, i)
+// ValidationKey returns the key of the rules of a country and payment scheme in
+// CountriesValidationInfos, e.g. GB/FPS, or the country alone when scheme is empty.
+func ValidationKey(country, scheme string) string {
+	if scheme == "" {
+		return country
+	}
+	return country + string(schemeSeparator) + scheme
+}
 
-		if currentState == finalState {
-			return countriesValidationInfos, nil
+// overlapping returns the period of the country that overlaps period, if any.
+func (countryValidationInfo *CountryValidationInfo) overlapping(period *CountryValidationInfo) *CountryValidationInfo {
+	for existing := countryValidationInfo; existing != nil; existing = existing.next {
+		if existing.overlaps(period) {
+			return existing
 		}
-		if currentState == invalidState {
-			return nil, stateError
+	}
+	return nil
+}
+
+// add inserts a period in the periods of the country, keeping them ordered by since.
+func (countryValidationInfo *CountryValidationInfo) add(period *CountryValidationInfo) *CountryValidationInfo {
+	periods := countryValidationInfo
+	link := &periods
+	for *link != nil && !period.since.Before((*link).since) {
+		link = &(*link).next
+	}
+	period.next = *link
+	*link = period
+	return periods
+}
+
+func (countryValidationInfo *CountryValidationInfo) isDated() bool {
+	return !countryValidationInfo.since.IsZero() || !countryValidationInfo.until.IsZero()
+}
+
+func (countryValidationInfo *CountryValidationInfo) overlaps(other *CountryValidationInfo) bool {
+	return (countryValidationInfo.until.IsZero() || other.since.Before(countryValidationInfo.until)) &&
+		(other.until.IsZero() || countryValidationInfo.since.Before(other.until))
+}
+
+// at returns the rules of the country in effect at a time, or nil when there are none.
+func (countryValidationInfo *CountryValidationInfo) at(t time.Time) *CountryValidationInfo {
+	for period := countryValidationInfo; period != nil; period = period.next {
+		if period.inEffect(t) {
+			return period
 		}
 	}
+	return nil
+}
 
-	return countriesValidationInfos, nil
+// inEffect reports whether t is in the period of the rules.
+func (countryValidationInfo *CountryValidationInfo) inEffect(t time.Time) bool {
+	return (countryValidationInfo.since.IsZero() || !t.Before(countryValidationInfo.since)) &&
+		(countryValidationInfo.until.IsZero() || t.Before(countryValidationInfo.until))
 }
 
-func IsLetter(c byte) bool {
-	return !((c < 'a' || c > 'z') && (c < 'A' || c > 'Z'))
+func expectArgs(rule *RuleNode, min, max int) error {
+	if len(rule.Args) >= min && len(rule.Args) <= max {
+		return nil
+	}
+	expected := fmt.Sprintf("%d to %d", min, max)
+	if min == max {
+		expected = strconv.Itoa(min)
+	}
+	return &TagError{Code: CodeInvalidArgument, Reason: fmt.Sprintf("rule %s takes %s arguments but found %d", rule.Name, expected, len(rule.Args)), Position: rule.Span.Start}
 }
 
-func IsNumeric(c byte) bool {
-	_, err := strconv.ParseFloat(string(c), 64)
-	return err == nil
+// MaxLength bounds the lengths a tag may give, so that a typo such as an extra
+// digit does not compile. Set it before compiling any tag.
+var MaxLength = 1 << 16
+
+// expectLengthArgs reads the minimum and maximum of a length rule, a single
+// argument being both.
+func expectLengthArgs(rule *RuleNode) (int, int, error) {
+	if err := expectArgs(rule, 1, 2); err != nil {
+		return 0, 0, err
+	}
+	lengths := make([]int, 0, len(rule.Args))
+	for _, arg := range rule.Args {
+		if !isDecimal(arg.Value) {
+			return 0, 0, &TagError{Code: CodeInvalidArgument, Reason: fmt.Sprintf("rule %s expects numeric arguments but found %s", rule.Name, arg.Value), Position: arg.Span.Start}
+		}
+		length, err := strconv.Atoi(arg.Value)
+		if err != nil || length > MaxLength {
+			return 0, 0, &TagError{Code: CodeLengthOutOfRange, Reason: fmt.Sprintf("length %s is greater than the maximum length %d", arg.Value, MaxLength), Position: arg.Span.Start}
+		}
+		lengths = append(lengths, length)
+	}
+
+	minLen, maxLen := lengths[0], lengths[len(lengths)-1]
+	if maxLen == 0 {
+		return 0, 0, &TagError{Code: CodeZeroMaxLength, Reason: "maximum length is zero", Position: rule.Span.Start}
+	}
+	if minLen > maxLen {
+		return 0, 0, &TagError{Code: CodeMinGreaterThanMax, Reason: fmt.Sprintf("minimum length %d greater than maximum length %d", minLen, maxLen), Position: rule.Span.Start}
+	}
+	return minLen, maxLen, nil
+}
+
+func isDecimal(str string) bool {
+	if str == "" {
+		return false
+	}
+	for i := 0; i < len(str); i++ {
+		if !IsNumeric(str[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// dateLayouts are the layouts accepted by since and until, dates being midnight UTC.
+var dateLayouts = []string{"2006-01-02", time.RFC3339}
+
+func expectDateArg(rule *RuleNode) (time.Time, error) {
+	if err := expectArgs(rule, 1, 1); err != nil {
+		return time.Time{}, err
+	}
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, rule.Args[0].Value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, &TagError{Code: CodeInvalidArgument, Reason: fmt.Sprintf("rule %s expects a date but found %s", rule.Name, rule.Args[0].Value), Position: rule.Args[0].Span.Start}
 }
 
-func ParseToInt(str string) int {
-	parsedInt, _ := strconv.ParseFloat(str, 64)
-	return int(parsedInt)
+func expectPeriod(countryValidationInfo *CountryValidationInfo, rule *RuleNode) error {
+	if countryValidationInfo.since.IsZero() || countryValidationInfo.until.IsZero() || countryValidationInfo.since.Before(countryValidationInfo.until) {
+		return nil
+	}
+	return &TagError{Code: CodeInvalidArgument, Reason: "rules end before they start", Position: rule.Span.Start}
 }
 
-func createUnexpectedSymbolError(unexpectedSymbol byte, position int) error {
-	return fmt.Errorf("unexpected %s symbol in position %d", string(unexpectedSymbol), position)
+func IsLetter(c byte) bool {
+	return !((c < 'a' || c > 'z') && (c < 'A' || c > 'Z'))
 }
 
-func createUnexpectedTokenError(unexpectedToken string, position int) error {
-	return fmt.Errorf("unexpected token %s in position %d", unexpectedToken, position)
+func IsNumeric(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func createUnexpectedTokenError(tag, unexpectedToken string, position int) error {
+	var knownRules []string
+	for rule := range ruleCompilers {
+		knownRules = append(knownRules, rule)
+	}
+	sort.Strings(knownRules)
+	return &TagError{Code: CodeUnexpectedToken, Tag: tag, Reason: fmt.Sprintf("unexpected token %s", unexpectedToken), Position: position, Expected: knownRules}
 }
@@ -1,128 +1,485 @@
 package tags
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
+// compileCases also seed FuzzCompileCountriesValidationInfos.
+var compileCases = []struct {
+	description                   string
+	validationStr                 string
+	hasErrors                     bool
+	expectedErrorMessage          string
+	expectedCountryValidationInfo map[string]*CountryValidationInfo
+}{
+	{
+		description:          "fails validation due to unexpected symbol [INITIAL_STATE]",
+		validationStr:        ":GB:7-10,required | PT:5]",
+		hasErrors:            true,
+		expectedErrorMessage: "unexpected : symbol in position 0, expected '['",
+	},
+	{
+		description:          "fails validation due to unexpected symbol [ASSEMBLING_COUNTRY_CODE_STATE]",
+		validationStr:        "[`GB:7-10,required | PT:5]",
+		hasErrors:            true,
+		expectedErrorMessage: "unexpected ` symbol in position 1, expected country code",
+	},
+	{
+		description:          "fails validation due to unexpected symbol [ASSEMBLING_COUNTRY_CODE_STATE]",
+		validationStr:        "[GB->7-10,required | PT:5]",
+		hasErrors:            true,
+		expectedErrorMessage: "unexpected - symbol in position 3, expected '=', '/', ',' or ':'",
+	},
+	{
+		description:          "fails validation due to unexpected symbol - after symbol :  [ASSEMBLING_COUNTRY_VALIDATION]",
+		validationStr:        " [GB:-10 | PT:5]",
+		hasErrors:            true,
+		expectedErrorMessage: "unexpected - symbol in position 5, expected length or rule",
+	},
+	{
+		description:          "fails validation due to unexpected symbol + after symbol 1  [ASSEMBLING_COUNTRY_VALIDATION]",
+		validationStr:        "[GB:1+10 | PT:5]",
+		hasErrors:            true,
+		expectedErrorMessage: "unexpected + symbol in position 5, expected '-', ',', rule, '|' or ']'",
+	},
+	{
+		description:                   "success validation with country GB and size equal to 1-10  [ASSEMBLING_COUNTRY_VALIDATION]",
+		validationStr:                 " [GB:1-10]",
+		hasErrors:                     false,
+		expectedCountryValidationInfo: map[string]*CountryValidationInfo{"GB": {minLen: 1, maxLen: 10}},
+	},
+	{
+		description:                   "success validation with country GB and size equal to 2-120  [ASSEMBLING_COUNTRY_VALIDATION]",
+		validationStr:                 " [GB:12-120]",
+		hasErrors:                     false,
+		expectedCountryValidationInfo: map[string]*CountryValidationInfo{"GB": {minLen: 12, maxLen: 120}},
+	},
+	{
+		description:                   "success validation with country GB and size equal to 10",
+		validationStr:                 " [GB:10 ]",
+		hasErrors:                     false,
+		expectedCountryValidationInfo: map[string]*CountryValidationInfo{"GB": {minLen: 10, maxLen: 10}},
+	},
+	{
+		description:                   "success validation with country GB and size equal to 10 to 765 and is required [ASSEMBLING_COUNTRY_VALIDATION]",
+		validationStr:                 " [GB:10-765, required ]",
+		hasErrors:                     false,
+		expectedCountryValidationInfo: map[string]*CountryValidationInfo{"GB": {minLen: 10, maxLen: 765, required: true}},
+	},
+	{
+		description:          "success validation with country GB and size equal to 10 to 765 and is required [ASSEMBLING_COUNTRY_VALIDATION]",
+		validationStr:        " [GB:10-765, ]",
+		hasErrors:            true,
+		expectedErrorMessage: "unexpected ] symbol in position 13, expected length or rule",
+	},
+	{
+		description:          "success validation with country GB and size equal to 10 to 765 and is required [ASSEMBLING_COUNTRY_VALIDATION]",
+		validationStr:        " [GB:10-765, | ]",
+		hasErrors:            true,
+		expectedErrorMessage: "unexpected | symbol in position 13, expected length or rule",
+	},
+	{
+		description:                   "success validation",
+		validationStr:                 " [ GB:7-10,required | PT:5]",
+		hasErrors:                     false,
+		expectedCountryValidationInfo: map[string]*CountryValidationInfo{"GB": {minLen: 7, maxLen: 10, required: true}, "PT": {minLen: 5, maxLen: 5}},
+	},
+	{
+		description:                   "success validation",
+		validationStr:                 "[GB:7-10,required | PT:5 | AU:10-12, required]",
+		hasErrors:                     false,
+		expectedCountryValidationInfo: map[string]*CountryValidationInfo{"GB": {minLen: 7, maxLen: 10, required: true}, "PT": {minLen: 5, maxLen: 5}, "AU": {minLen: 10, maxLen: 12, required: true}},
+	},
+	{
+		description:                   "success validation",
+		validationStr:                 "[GB:required]",
+		hasErrors:                     false,
+		expectedCountryValidationInfo: map[string]*CountryValidationInfo{"GB": {required: true}},
+	},
+	{
+		description:                   "success validation with token followed by separators",
+		validationStr:                 "[GB:required,7 |PT:required]",
+		hasErrors:                     false,
+		expectedCountryValidationInfo: map[string]*CountryValidationInfo{"GB": {minLen: 7, maxLen: 7, required: true}, "PT": {required: true}},
+	},
+	{
+		description:          "fails validation due to unexpected symbol inside token",
+		validationStr:        "[GB:requi+red]",
+		hasErrors:            true,
+		expectedErrorMessage: "unexpected + symbol in position 9, expected '(', ',', rule, '|' or ']'",
+	},
+	{
+		description:          "success validation",
+		validationStr:        "[GB:7-10,required | AU:5 | AU:10-12, required]",
+		hasErrors:            true,
+		expectedErrorMessage: "country AU defined twice in position 27",
+	},
+}
+
 // Expected template:
 // [{country_code}:{validation},{validation} | {country_code}:{validation}]
 func Test_RetrieveValidateFieldsCreatesValidationStructAsExpected(t *testing.T) {
+	for _, c := range compileCases {
+		t.Run(c.description, func(t *testing.T) {
+			cInfo, err := CompileCountriesValidationInfos(c.validationStr)
+			if c.hasErrors {
+				assert.NotNil(t, err)
+				assert.Equal(t, c.expectedErrorMessage, err.(*TagError).Summary())
+			} else {
+				assert.Nil(t, err)
+				assert.True(t, assertEquals(&c.expectedCountryValidationInfo, &cInfo))
+			}
+
+		})
+	}
+}
+
+func Test_CompileCountriesValidationInfosReportsErrorCodes(t *testing.T) {
 	cases := []struct {
-		description                   string
-		validationStr                 string
-		hasErrors                     bool
-		expectedErrorMessage          string
-		expectedCountryValidationInfo map[string]*CountryValidationInfo
+		description          string
+		validationStr        string
+		expectedCode         ErrorCode
+		expectedErrorMessage string
 	}{
 		{
-			description:          "fails validation due to unexpected symbol [INITIAL_STATE]",
-			validationStr:        ":GB:7-10,required | PT:5]",
-			hasErrors:            true,
-			expectedErrorMessage: "unexpected : symbol in position 0",
+			description:          "unterminated tag after a length",
+			validationStr:        "[GB:7-10",
+			expectedCode:         CodeUnterminatedTag,
+			expectedErrorMessage: "unexpected end of tag in position 8, expected ',', rule, '|' or ']'",
 		},
 		{
-			description:          "fails validation due to unexpected symbol [ASSEMBLING_COUNTRY_CODE_STATE]",
-			validationStr:        "[`GB:7-10,required | PT:5]",
-			hasErrors:            true,
-			expectedErrorMessage: "unexpected ` symbol in position 1",
+			description:          "unterminated tag after a token",
+			validationStr:        "[GB:required",
+			expectedCode:         CodeUnterminatedTag,
+			expectedErrorMessage: "unexpected end of tag in position 12, expected '(', ',', rule, '|' or ']'",
 		},
 		{
-			description:          "fails validation due to unexpected symbol [ASSEMBLING_COUNTRY_CODE_STATE]",
-			validationStr:        "[GB->7-10,required | PT:5]",
-			hasErrors:            true,
-			expectedErrorMessage: "unexpected - symbol in position 3",
+			description:          "empty tag",
+			validationStr:        "",
+			expectedCode:         CodeUnterminatedTag,
+			expectedErrorMessage: "unexpected end of tag in position 0, expected '['",
 		},
 		{
-			description:          "fails validation due to unexpected symbol - after symbol :  [ASSEMBLING_COUNTRY_VALIDATION]",
-			validationStr:        " [GB:-10 | PT:5]",
-			hasErrors:            true,
-			expectedErrorMessage: "unexpected - symbol in position 5",
+			description:          "trailing garbage after closer",
+			validationStr:        "[GB:7]junk",
+			expectedCode:         CodeTrailingInput,
+			expectedErrorMessage: "unexpected junk after ] in position 6, expected end of tag",
 		},
 		{
-			description:          "fails validation due to unexpected symbol + after symbol 1  [ASSEMBLING_COUNTRY_VALIDATION]",
-			validationStr:        "[GB:1+10 | PT:5]",
-			hasErrors:            true,
-			expectedErrorMessage: "unexpected + symbol in position 5",
+			description:          "trailing garbage after closer and spaces",
+			validationStr:        "[GB:7]  ]",
+			expectedCode:         CodeTrailingInput,
+			expectedErrorMessage: "unexpected ] after ] in position 8, expected end of tag",
 		},
 		{
-			description:                   "success validation with country GB and size equal to 1-10  [ASSEMBLING_COUNTRY_VALIDATION]",
-			validationStr:                 " [GB:1-10]",
-			hasErrors:                     false,
-			expectedCountryValidationInfo: map[string]*CountryValidationInfo{"GB": {minLen: 1, maxLen: 10}},
+			description:          "unexpected symbol",
+			validationStr:        "[GB:7+]",
+			expectedCode:         CodeUnexpectedSymbol,
+			expectedErrorMessage: "unexpected + symbol in position 5, expected '-', ',', rule, '|' or ']'",
 		},
 		{
-			description:                   "success validation with country GB and size equal to 2-120  [ASSEMBLING_COUNTRY_VALIDATION]",
-			validationStr:                 " [GB:12-120]",
-			hasErrors:                     false,
-			expectedCountryValidationInfo: map[string]*CountryValidationInfo{"GB": {minLen: 12, maxLen: 120}},
+			description:          "unexpected token",
+			validationStr:        "[GB:optional]",
+			expectedCode:         CodeUnexpectedToken,
+			expectedErrorMessage: "unexpected token optional in position 4, expected charset, digitsonly, eqfield, gtfield, lower, ltfield, nefield, nospace, oneof, pattern, required, required_if, required_unless, since, trim, until or upper",
 		},
 		{
-			description:                   "success validation with country GB and size equal to 10",
-			validationStr:                 " [GB:10 ]",
-			hasErrors:                     false,
-			expectedCountryValidationInfo: map[string]*CountryValidationInfo{"GB": {minLen: 10, maxLen: 10}},
-		},
-		{
-			description:                   "success validation with country GB and size equal to 10 to 765 and is required [ASSEMBLING_COUNTRY_VALIDATION]",
-			validationStr:                 " [GB:10-765, required ]",
-			hasErrors:                     false,
-			expectedCountryValidationInfo: map[string]*CountryValidationInfo{"GB": {minLen: 10, maxLen: 765, required: true}},
+			description:          "duplicate country",
+			validationStr:        "[GB:7 | GB:8]",
+			expectedCode:         CodeDuplicateCountry,
+			expectedErrorMessage: "country GB defined twice in position 8",
 		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			_, err := CompileCountriesValidationInfos(c.validationStr)
+
+			tagError, ok := err.(*TagError)
+			assert.True(t, ok)
+			assert.Equal(t, c.expectedCode, tagError.Code)
+			assert.Equal(t, c.expectedErrorMessage, tagError.Summary())
+		})
+	}
+}
+
+func Test_CompileCountriesValidationInfosAcceptsTrailingSpaces(t *testing.T) {
+	cInfo, err := CompileCountriesValidationInfos("[GB:7]   ")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 7, cInfo["GB"].maxLen)
+}
+
+func Test_CompileTagAppliesRulesToEveryCountryOfAClause(t *testing.T) {
+	cInfo, err := CompileCountriesValidationInfos("[GB,IE:7-10,required | PT:5]")
+
+	assert.Nil(t, err)
+	assert.True(t, assertEquals(&map[string]*CountryValidationInfo{
+		"GB": {minLen: 7, maxLen: 10, required: true},
+		"IE": {minLen: 7, maxLen: 10, required: true},
+		"PT": {minLen: 5, maxLen: 5},
+	}, &cInfo))
+}
+
+func Test_CompileTagNarrowsSelectorsOfDifferentDimensions(t *testing.T) {
+	cInfo, err := CompileCountriesValidationInfos("[GB/FPS,GB/BACS,IE,currency=EUR:6 | currency=GBP,scheme=FPS:7]")
+
+	assert.Nil(t, err)
+	assert.True(t, assertEquals(&map[string]*CountryValidationInfo{
+		"GB/FPS,currency=EUR":     {minLen: 6, maxLen: 6},
+		"GB/BACS,currency=EUR":    {minLen: 6, maxLen: 6},
+		"IE,currency=EUR":         {minLen: 6, maxLen: 6},
+		"scheme=FPS,currency=GBP": {minLen: 7, maxLen: 7},
+	}, &cInfo))
+}
+
+func Test_CompileTagKeepsSchemesOfTheirCountry(t *testing.T) {
+	cases := []struct {
+		description       string
+		validationStr     string
+		expectedSelectors []string
+	}{
+		{description: "schemes of different countries", validationStr: "[GB/FPS,IE/BACS:5]", expectedSelectors: []string{"GB/FPS", "IE/BACS"}},
+		{description: "country with a scheme of another", validationStr: "[GB,IE/FPS:5]", expectedSelectors: []string{"GB", "IE/FPS"}},
+		{description: "country narrowed by a scheme", validationStr: "[GB,IE,scheme=FPS:5]", expectedSelectors: []string{"GB/FPS", "IE/FPS"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			cInfo, err := CompileCountriesValidationInfos(c.validationStr)
+
+			assert.Nil(t, err)
+			var selectors []string
+			for key := range cInfo {
+				selectors = append(selectors, key)
+			}
+			assert.ElementsMatch(t, c.expectedSelectors, selectors)
+		})
+	}
+}
+
+func Test_CompileTagRejectsSchemesNarrowingSchemes(t *testing.T) {
+	_, err := CompileCountriesValidationInfos("[GB/FPS,scheme=BACS:5]")
+
+	assert.NotNil(t, err)
+	assert.Equal(t, CodeConflictingSelector, err.(*TagError).Code)
+	assert.Equal(t, "scheme=BACS cannot narrow GB/FPS, which already selects a scheme in position 8", err.(*TagError).Summary())
+}
+
+func Test_CompileTagRejectsSelectorsDefinedTwice(t *testing.T) {
+	cases := []struct {
+		description          string
+		validationStr        string
+		expectedErrorMessage string
+	}{
 		{
-			description:          "success validation with country GB and size equal to 10 to 765 and is required [ASSEMBLING_COUNTRY_VALIDATION]",
-			validationStr:        " [GB:10-765, ]",
-			hasErrors:            true,
-			expectedErrorMessage: "unexpected ] symbol in position 13",
+			description:          "terms in another order",
+			validationStr:        "[GB,currency=EUR:6 | currency=EUR,country=GB:7]",
+			expectedErrorMessage: "selector GB,currency=EUR defined twice in position 34",
 		},
 		{
-			description:          "success validation with country GB and size equal to 10 to 765 and is required [ASSEMBLING_COUNTRY_VALIDATION]",
-			validationStr:        " [GB:10-765, | ]",
-			hasErrors:            true,
-			expectedErrorMessage: "unexpected | symbol in position 13",
+			description:          "term repeated in the same clause",
+			validationStr:        "[currency=EUR,currency=EUR:6]",
+			expectedErrorMessage: "selector currency=EUR defined twice in position 14",
 		},
 		{
-			description:                   "success validation",
-			validationStr:                 " [ GB:7-10,required | PT:5]",
-			hasErrors:                     false,
-			expectedCountryValidationInfo: map[string]*CountryValidationInfo{"GB": {minLen: 7, maxLen: 10, required: true}, "PT": {minLen: 5, maxLen: 5}},
+			description:          "country written as a dimension",
+			validationStr:        "[GB:6 | country=GB:7]",
+			expectedErrorMessage: "country GB defined twice in position 8",
 		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			_, err := CompileCountriesValidationInfos(c.validationStr)
+
+			assert.NotNil(t, err)
+			assert.Equal(t, CodeDuplicateCountry, err.(*TagError).Code)
+			assert.Equal(t, c.expectedErrorMessage, err.(*TagError).Summary())
+		})
+	}
+}
+
+func Test_CompileTagCompilesPatternAndOneOf(t *testing.T) {
+	cInfo, err := CompileCountriesValidationInfos("[GB:pattern('^[0-9]+$'), oneof(GBP, 'EUR')]")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "^[0-9]+$", cInfo["GB"].pattern.String())
+	assert.Equal(t, []string{"GBP", "EUR"}, cInfo["GB"].oneOf)
+}
+
+func Test_CompileTagOrdersPeriodsOfACountry(t *testing.T) {
+	cInfo, err := CompileCountriesValidationInfos("[GB:1-35,since(2026-03-01) | GB:1-18,until(2026-03-01) | PT:5]")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 18, cInfo["GB"].maxLen)
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), cInfo["GB"].until)
+	assert.Equal(t, 35, cInfo["GB"].next.maxLen)
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), cInfo["GB"].next.since)
+	assert.Nil(t, cInfo["GB"].next.next)
+}
+
+func Test_CompileTagRejectsOverlappingPeriods(t *testing.T) {
+	cases := []struct {
+		description          string
+		validationStr        string
+		expectedErrorMessage string
+	}{
 		{
-			description:                   "success validation",
-			validationStr:                 "[GB:7-10,required | PT:5 | AU:10-12, required]",
-			hasErrors:                     false,
-			expectedCountryValidationInfo: map[string]*CountryValidationInfo{"GB": {minLen: 7, maxLen: 10, required: true}, "PT": {minLen: 5, maxLen: 5}, "AU": {minLen: 10, maxLen: 12, required: true}},
+			description:          "undated rules overlap any period",
+			validationStr:        "[GB:1-18 | GB:1-35,since(2026-03-01)]",
+			expectedErrorMessage: "country GB defined twice for overlapping dates in position 11",
 		},
 		{
-			description:                   "success validation",
-			validationStr:                 "[GB:required]",
-			hasErrors:                     false,
-			expectedCountryValidationInfo: map[string]*CountryValidationInfo{"GB": {required: true}},
+			description:          "open periods overlap",
+			validationStr:        "[GB:1-18,since(2026-01-01) | GB:1-35,since(2026-03-01)]",
+			expectedErrorMessage: "country GB defined twice for overlapping dates in position 29",
 		},
 		{
-			description:          "success validation",
-			validationStr:        "[GB:7-10,required | AU:5 | AU:10-12, required]",
-			hasErrors:            true,
-			expectedErrorMessage: "country defined twice",
+			description:          "until is excluded but the day before is not",
+			validationStr:        "[GB:1-18,until(2026-03-02) | GB:1-35,since(2026-03-01)]",
+			expectedErrorMessage: "country GB defined twice for overlapping dates in position 29",
 		},
 	}
 
 	for _, c := range cases {
 		t.Run(c.description, func(t *testing.T) {
-			cInfo, err := CompileCountriesValidationInfos(c.validationStr)
-			if c.hasErrors {
-				assert.NotNil(t, err)
-				assert.Equal(t, c.expectedErrorMessage, err.Error())
-			} else {
-				assert.Nil(t, err)
-				assert.True(t, assertEquals(&c.expectedCountryValidationInfo, &cInfo))
-			}
+			_, err := CompileCountriesValidationInfos(c.validationStr)
 
+			assert.NotNil(t, err)
+			assert.Equal(t, CodeDuplicateCountry, err.(*TagError).Code)
+			assert.Equal(t, c.expectedErrorMessage, err.(*TagError).Summary())
 		})
 	}
 }
 
+// invalidRuleArgumentCases also seed FuzzCompileCountriesValidationInfos.
+var invalidRuleArgumentCases = []struct {
+	description          string
+	validationStr        string
+	expectedErrorMessage string
+}{
+	{
+		description:          "required takes no arguments",
+		validationStr:        "[GB:required(yes)]",
+		expectedErrorMessage: "rule required takes 0 arguments but found 1 in position 4",
+	},
+	{
+		description:          "length is not a named rule",
+		validationStr:        "[GB:length(1,2)]",
+		expectedErrorMessage: "unexpected token length in position 4, expected charset, digitsonly, eqfield, gtfield, lower, ltfield, nefield, nospace, oneof, pattern, required, required_if, required_unless, since, trim, until or upper",
+	},
+	{
+		description:          "length literals must fit an int",
+		validationStr:        "[GB:99999999999999999999]",
+		expectedErrorMessage: "length 99999999999999999999 is greater than the maximum length 65536 in position 4",
+	},
+	{
+		description:          "lengths are bounded by MaxLength",
+		validationStr:        "[GB:1-65537]",
+		expectedErrorMessage: "length 65537 is greater than the maximum length 65536 in position 6",
+	},
+	{
+		description:          "length units are bytes, runes or graphemes",
+		validationStr:        "[GB:chars:1-35]",
+		expectedErrorMessage: "unknown length unit chars in position 4, expected bytes, runes or graphemes",
+	},
+	{
+		description:          "charset takes a registered charset",
+		validationStr:        "[GB:charset(ascii)]",
+		expectedErrorMessage: "unknown charset ascii in position 12, expected fps, sepa or swiftx",
+	},
+	{
+		description:          "required_if takes a field",
+		validationStr:        "[GB:required_if(BankIdCode, SortCode)]",
+		expectedErrorMessage: "rule required_if takes 1 arguments but found 2 in position 4",
+	},
+	{
+		description:          "required_unless takes a field",
+		validationStr:        "[GB:required_unless]",
+		expectedErrorMessage: "rule required_unless takes 1 arguments but found 0 in position 4",
+	},
+	{
+		description:          "comparisons take a field",
+		validationStr:        "[GB:gtfield()]",
+		expectedErrorMessage: "rule gtfield takes 1 arguments but found 0 in position 4",
+	},
+	{
+		description:          "min greater than max",
+		validationStr:        "[GB:10-7]",
+		expectedErrorMessage: "minimum length 10 greater than maximum length 7 in position 4",
+	},
+	{
+		description:          "zero max",
+		validationStr:        "[GB:0]",
+		expectedErrorMessage: "maximum length is zero in position 4",
+	},
+	{
+		description:          "zero max of a range",
+		validationStr:        "[GB:3-0]",
+		expectedErrorMessage: "maximum length is zero in position 4",
+	},
+	{
+		description:          "pattern takes a regular expression",
+		validationStr:        "[GB:pattern('[a-z')]",
+		expectedErrorMessage: "rule pattern expects a regular expression but found [a-z in position 12",
+	},
+	{
+		description:          "oneof takes at least one argument",
+		validationStr:        "[GB:oneof()]",
+		expectedErrorMessage: "rule oneof takes at least 1 argument but found 0 in position 4",
+	},
+	{
+		description:          "since takes a date",
+		validationStr:        "[GB:since(2026-02-30)]",
+		expectedErrorMessage: "rule since expects a date but found 2026-02-30 in position 10",
+	},
+	{
+		description:          "until takes a date",
+		validationStr:        "[GB:until(soon)]",
+		expectedErrorMessage: "rule until expects a date but found soon in position 10",
+	},
+	{
+		description:          "until comes after since",
+		validationStr:        "[GB:since(2026-03-01),until(2026-03-01)]",
+		expectedErrorMessage: "rules end before they start in position 22",
+	},
+	{
+		description:          "country repeated in the same clause",
+		validationStr:        "[GB,GB:7]",
+		expectedErrorMessage: "country GB defined twice in position 4",
+	},
+}
+
+func Test_CompileTagRejectsInvalidRuleArguments(t *testing.T) {
+	for _, c := range invalidRuleArgumentCases {
+		t.Run(c.description, func(t *testing.T) {
+			_, err := CompileCountriesValidationInfos(c.validationStr)
+
+			assert.NotNil(t, err)
+			assert.Equal(t, c.validationStr, err.(*TagError).Tag)
+			assert.Equal(t, c.expectedErrorMessage, err.(*TagError).Summary())
+		})
+	}
+}
+
+func Test_MaxLengthBoundsLengths(t *testing.T) {
+	defer func(maxLength int) { MaxLength = maxLength }(MaxLength)
+	MaxLength = 35
+
+	_, err := CompileCountriesValidationInfos("[GB:1-35]")
+	assert.Nil(t, err)
+
+	_, err = CompileCountriesValidationInfos("[GB:1-140]")
+	assert.NotNil(t, err)
+	assert.Equal(t, "length 140 is greater than the maximum length 35 in position 6", err.(*TagError).Summary())
+}
+
 func Test_IsLetterWorks(t *testing.T) {
 	cases := []struct {
 		description    string
@@ -182,7 +539,7 @@ func Test_IsNumericWorks(t *testing.T) {
 	}
 }
 
-func assertEquals(expectedCountryInfo, actualCountryInfo *map[string]*CountryValidationInfo) bool {
+func assertEquals(expectedCountryInfo *map[string]*CountryValidationInfo, actualCountryInfo *CountriesValidationInfos) bool {
 	for k, expected := range *expectedCountryInfo {
 		actual := (*actualCountryInfo)[k]
 		if actual == nil {
@@ -194,3 +551,16 @@ func assertEquals(expectedCountryInfo, actualCountryInfo *map[string]*CountryVal
 	}
 	return true
 }
+
+func Test_CompileTagBoundsSelectorsOfAClause(t *testing.T) {
+	var terms []string
+	for dimension := 0; dimension < 11; dimension++ {
+		terms = append(terms, fmt.Sprintf("d%d=0", dimension), fmt.Sprintf("d%d=1", dimension))
+	}
+
+	_, err := CompileCountriesValidationInfos("[GB:1 | " + strings.Join(terms, ",") + ":1]")
+
+	assert.NotNil(t, err)
+	assert.Equal(t, CodeTooManySelectors, err.(*TagError).Code)
+	assert.Equal(t, "clause selects more than 1024 combinations of dimensions in position 8", err.(*TagError).Summary())
+}
@@ -0,0 +1,117 @@
+package tags
+
+import "strings"
+
+// TokenKind classifies the tokens produced by the lexer.
+type TokenKind string
+
+const (
+	TokenOpen       TokenKind = "OPEN"
+	TokenClose      TokenKind = "CLOSE"
+	TokenColon      TokenKind = "COLON"
+	TokenPipe       TokenKind = "PIPE"
+	TokenComma      TokenKind = "COMMA"
+	TokenDash       TokenKind = "DASH"
+	TokenSlash      TokenKind = "SLASH"
+	TokenEquals     TokenKind = "EQUALS"
+	TokenLParen     TokenKind = "LPAREN"
+	TokenRParen     TokenKind = "RPAREN"
+	TokenIdentifier TokenKind = "IDENTIFIER"
+	TokenNumber     TokenKind = "NUMBER"
+	TokenString     TokenKind = "STRING"
+	TokenIllegal    TokenKind = "ILLEGAL"
+	TokenEOF        TokenKind = "EOF"
+)
+
+var symbolTokens = map[Symbol]TokenKind{
+	validationOpener:             TokenOpen,
+	validationCloser:             TokenClose,
+	countryValidationInitializer: TokenColon,
+	countrySeparator:             TokenPipe,
+	validationSeparator:          TokenComma,
+	numericLengthSeparator:       TokenDash,
+	schemeSeparator:              TokenSlash,
+	dimensionValueSeparator:      TokenEquals,
+	argumentsOpener:              TokenLParen,
+	argumentsCloser:              TokenRParen,
+}
+
+// Span is the half open byte range [Start, End) of a token or node in the tag.
+type Span struct {
+	Start int
+	End   int
+}
+
+// Token is a lexeme of an f3_validate tag. Text is the unquoted value for strings.
+type Token struct {
+	Kind TokenKind
+	Text string
+	Span Span
+}
+
+// Lex splits a tag into tokens, always ending with a TokenEOF. Symbols that
+// are not part of the language become TokenIllegal so the parser can report
+// them with the set of tokens it expected at that point.
+func Lex(tag string) []Token {
+	var tokens []Token
+	position := 0
+
+	for position < len(tag) {
+		c := tag[position]
+		start := position
+
+		switch {
+		case isSpace(c):
+			position++
+			continue
+		case IsLetter(c):
+			for position < len(tag) && (IsLetter(tag[position]) || IsNumeric(tag[position]) || tag[position] == '_') {
+				position++
+			}
+			tokens = append(tokens, Token{Kind: TokenIdentifier, Text: tag[start:position], Span: Span{start, position}})
+		case IsNumeric(c):
+			for position < len(tag) && IsNumeric(tag[position]) {
+				position++
+			}
+			tokens = append(tokens, Token{Kind: TokenNumber, Text: tag[start:position], Span: Span{start, position}})
+		case Symbol(c) == stringDelimiter:
+			tokens = append(tokens, lexString(tag, &position))
+		default:
+			position++
+			kind, isSymbol := symbolTokens[Symbol(c)]
+			if !isSymbol {
+				kind = TokenIllegal
+			}
+			tokens = append(tokens, Token{Kind: kind, Text: tag[start:position], Span: Span{start, position}})
+		}
+	}
+
+	return append(tokens, Token{Kind: TokenEOF, Span: Span{len(tag), len(tag)}})
+}
+
+// lexString reads a single quoted string. A backslash escapes the next byte.
+// An unterminated string is returned as TokenIllegal spanning the rest of the tag.
+func lexString(tag string, position *int) Token {
+	start := *position
+	var value strings.Builder
+	*position++
+
+	for *position < len(tag) {
+		c := tag[*position]
+		*position++
+		if Symbol(c) == stringDelimiter {
+			return Token{Kind: TokenString, Text: value.String(), Span: Span{start, *position}}
+		}
+		if c == '\\' && *position < len(tag) {
+			c = tag[*position]
+			*position++
+		}
+		value.WriteByte(c)
+	}
+
+	return Token{Kind: TokenIllegal, Text: tag[start:], Span: Span{start, len(tag)}}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
@@ -0,0 +1,64 @@
+package tags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LexProducesTypedTokensWithSpans(t *testing.T) {
+	tokens := Lex("[GB,PT:7-10, oneof('a b',x_1)]")
+
+	var kinds []TokenKind
+	for _, token := range tokens {
+		kinds = append(kinds, token.Kind)
+	}
+
+	assert.Equal(t, []TokenKind{
+		TokenOpen, TokenIdentifier, TokenComma, TokenIdentifier, TokenColon,
+		TokenNumber, TokenDash, TokenNumber, TokenComma,
+		TokenIdentifier, TokenLParen, TokenString, TokenComma, TokenIdentifier, TokenRParen,
+		TokenClose, TokenEOF,
+	}, kinds)
+	assert.Equal(t, Token{Kind: TokenNumber, Text: "10", Span: Span{9, 11}}, tokens[7])
+	assert.Equal(t, Token{Kind: TokenString, Text: "a b", Span: Span{19, 24}}, tokens[11])
+	assert.Equal(t, Token{Kind: TokenEOF, Span: Span{30, 30}}, tokens[16])
+}
+
+func Test_LexReadsSchemeSeparator(t *testing.T) {
+	tokens := Lex("[GB/FPS:6]")
+
+	assert.Equal(t, Token{Kind: TokenSlash, Text: "/", Span: Span{3, 4}}, tokens[2])
+	assert.Equal(t, Token{Kind: TokenIdentifier, Text: "FPS", Span: Span{4, 7}}, tokens[3])
+}
+
+func Test_LexReadsDimensionValueSeparator(t *testing.T) {
+	tokens := Lex("[currency=EUR:6]")
+
+	assert.Equal(t, Token{Kind: TokenEquals, Text: "=", Span: Span{9, 10}}, tokens[2])
+	assert.Equal(t, Token{Kind: TokenIdentifier, Text: "EUR", Span: Span{10, 13}}, tokens[3])
+}
+
+func Test_LexMarksUnknownSymbolsAsIllegal(t *testing.T) {
+	cases := []struct {
+		description   string
+		tag           string
+		expectedToken Token
+	}{
+		{description: "unknown symbol", tag: "[GB+", expectedToken: Token{Kind: TokenIllegal, Text: "+", Span: Span{3, 4}}},
+		{description: "unterminated string", tag: "[GB:'ab", expectedToken: Token{Kind: TokenIllegal, Text: "'ab", Span: Span{4, 7}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			tokens := Lex(c.tag)
+			assert.Equal(t, c.expectedToken, tokens[len(tokens)-2])
+		})
+	}
+}
+
+func Test_LexUnescapesStrings(t *testing.T) {
+	tokens := Lex(`'it\'s'`)
+
+	assert.Equal(t, Token{Kind: TokenString, Text: "it's", Span: Span{0, 7}}, tokens[0])
+}
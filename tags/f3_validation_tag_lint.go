@@ -0,0 +1,30 @@
+package tags
+
+import "fmt"
+
+// LintTag reports what a parsed tag says that CompileTag accepts but is most
+// likely a mistake: unknown country codes and rules given twice in a clause.
+func LintTag(tag *TagNode) []*TagError {
+	var problems []*TagError
+	report := func(code ErrorCode, span Span, reason string, args ...interface{}) {
+		problems = append(problems, &TagError{Code: code, Tag: tag.Source, Reason: fmt.Sprintf(reason, args...), Position: span.Start})
+	}
+
+	for _, clause := range tag.Clauses {
+		for _, country := range clause.Countries {
+			if country.Code != "" && !IsKnownCountry(country.Code) {
+				report(CodeUnknownCountry, country.Span, "unknown country %s", country.Code)
+			}
+		}
+
+		seenRules := make(map[string]bool)
+		for _, rule := range clause.Rules {
+			if seenRules[rule.Name] {
+				report(CodeDuplicateRule, rule.Span, "rule %s given twice", rule.Name)
+			}
+			seenRules[rule.Name] = true
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,43 @@
+package tags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LintTagReportsLikelyMistakes(t *testing.T) {
+	cases := []struct {
+		description      string
+		tag              string
+		expectedProblems []string
+	}{
+		{description: "valid tag", tag: "[GB:7-10,required | PT:5]", expectedProblems: nil},
+		{description: "unknown country", tag: "[UK:7-10]", expectedProblems: []string{"unknown country UK in position 1"}},
+		{description: "unknown country in a list", tag: "[GB,XX:7]", expectedProblems: []string{"unknown country XX in position 4"}},
+		{description: "schemes are not checked", tag: "[GB/FPS:6 | UK/FPS:6]", expectedProblems: []string{"unknown country UK in position 12"}},
+		{description: "other dimensions are not checked", tag: "[currency=XXX:6 | country=UK:6]", expectedProblems: []string{"unknown country UK in position 18"}},
+		{description: "duplicate rule", tag: "[GB:required,7,required]", expectedProblems: []string{"rule required given twice in position 15"}},
+		{description: "duplicate length", tag: "[GB:7,8]", expectedProblems: []string{"rule length given twice in position 6"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			node, err := ParseTag(c.tag)
+			assert.Nil(t, err)
+
+			var problems []string
+			for _, problem := range LintTag(node) {
+				problems = append(problems, problem.Summary())
+			}
+			assert.Equal(t, c.expectedProblems, problems)
+		})
+	}
+}
+
+func Test_IsKnownCountry(t *testing.T) {
+	assert.True(t, IsKnownCountry("GB"))
+	assert.True(t, IsKnownCountry("PT"))
+	assert.False(t, IsKnownCountry("UK"))
+	assert.False(t, IsKnownCountry("gb"))
+}
@@ -0,0 +1,297 @@
+package tags
+
+import "fmt"
+
+// GrammarVersion is the version of docs/f3_validate.ebnf implemented by ParseTag.
+const GrammarVersion = "2.2"
+
+// ParseTag parses an f3_validate tag into its AST without giving meaning to the rules.
+// The productions quoted on the parse functions come from docs/f3_validate.ebnf.
+func ParseTag(tag string) (*TagNode, error) {
+	p := &parser{tag: tag, tokens: Lex(tag)}
+	return p.parseTag()
+}
+
+type parser struct {
+	tag    string
+	tokens []Token
+	next   int
+
+	// expected collects the descriptions of every token tried at the current
+	// position, so errors list exactly what the grammar would have accepted.
+	expected []string
+}
+
+func (p *parser) peek() Token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) advance() Token {
+	token := p.tokens[p.next]
+	if token.Kind != TokenEOF {
+		p.next++
+	}
+	p.expected = nil
+	return token
+}
+
+// at reports whether the next token is of the given kind, remembering the
+// description as expected when it is not.
+func (p *parser) at(kind TokenKind, description string) bool {
+	if p.peek().Kind == kind {
+		return true
+	}
+	p.expected = append(p.expected, description)
+	return false
+}
+
+func (p *parser) accept(kind TokenKind, description string) bool {
+	if p.at(kind, description) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(kind TokenKind, description string) (Token, error) {
+	if p.at(kind, description) {
+		return p.advance(), nil
+	}
+	return Token{}, p.unexpected()
+}
+
+func (p *parser) unexpected() error {
+	token := p.peek()
+	tagError := &TagError{Tag: p.tag, Position: token.Span.Start, Expected: p.expected}
+
+	switch token.Kind {
+	case TokenEOF:
+		tagError.Code = CodeUnterminatedTag
+		tagError.Reason = "unexpected end of tag"
+	case TokenIdentifier, TokenNumber, TokenString:
+		tagError.Code = CodeUnexpectedToken
+		tagError.Reason = fmt.Sprintf("unexpected token %s", token.Text)
+	default:
+		tagError.Code = CodeUnexpectedSymbol
+		tagError.Reason = fmt.Sprintf("unexpected %s symbol", token.Text)
+		if Symbol(token.Text[0]) == stringDelimiter {
+			tagError.Reason = "unterminated string"
+		}
+	}
+	return tagError
+}
+
+// Tag = "[" Clause { "|" Clause } "]" .
+func (p *parser) parseTag() (*TagNode, error) {
+	open, err := p.expect(TokenOpen, "'['")
+	if err != nil {
+		return nil, err
+	}
+	node := &TagNode{Source: p.tag}
+
+	for {
+		clause, err := p.parseClause()
+		if err != nil {
+			return nil, err
+		}
+		node.Clauses = append(node.Clauses, clause)
+
+		if p.accept(TokenPipe, "'|'") {
+			continue
+		}
+		closer, err := p.expect(TokenClose, "']'")
+		if err != nil {
+			return nil, err
+		}
+		node.Span = Span{open.Span.Start, closer.Span.End}
+		break
+	}
+
+	if trailing := p.peek(); trailing.Kind != TokenEOF {
+		return nil, &TagError{
+			Code:     CodeTrailingInput,
+			Tag:      p.tag,
+			Reason:   fmt.Sprintf("unexpected %s after %s", trailing.Text, string(validationCloser)),
+			Position: trailing.Span.Start,
+			Expected: []string{"end of tag"},
+		}
+	}
+	return node, nil
+}
+
+// Selector = Dimension | Country .
+// Dimension = identifier "=" Value .
+// Country = identifier [ "/" Scheme ] .
+func (p *parser) parseSelector() (*CountryNode, error) {
+	code, err := p.expect(TokenIdentifier, "country code")
+	if err != nil {
+		return nil, err
+	}
+	country := &CountryNode{Code: code.Text, Span: code.Span}
+
+	if p.accept(TokenEquals, "'='") {
+		value := p.peek()
+		if value.Kind != TokenIdentifier && value.Kind != TokenNumber {
+			p.expected = append(p.expected, "value")
+			return nil, p.unexpected()
+		}
+		p.advance()
+		country.Code = ""
+		switch code.Text {
+		case CountryDimension:
+			country.Code = value.Text
+		case SchemeDimension:
+			country.Scheme = value.Text
+		default:
+			country.Dimension, country.Value = code.Text, value.Text
+		}
+		country.Span.End = value.Span.End
+		return country, nil
+	}
+
+	if p.accept(TokenSlash, "'/'") {
+		scheme, err := p.expect(TokenIdentifier, "scheme")
+		if err != nil {
+			return nil, err
+		}
+		country.Scheme = scheme.Text
+		country.Span.End = scheme.Span.End
+	}
+	return country, nil
+}
+
+// Clause = Selectors ":" Rules .
+func (p *parser) parseClause() (*ClauseNode, error) {
+	clause := &ClauseNode{Span: Span{Start: p.peek().Span.Start}}
+
+	for {
+		country, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		clause.Countries = append(clause.Countries, country)
+
+		if !p.accept(TokenComma, "','") {
+			break
+		}
+	}
+	if _, err := p.expect(TokenColon, "':'"); err != nil {
+		return nil, err
+	}
+
+	for {
+		rule, err := p.parseRule()
+		if err != nil {
+			return nil, err
+		}
+		clause.Rules = append(clause.Rules, rule)
+		clause.Span.End = rule.Span.End
+
+		// Named rules may follow without a comma, lengths may not.
+		if !p.accept(TokenComma, "','") && (!p.at(TokenIdentifier, "rule") || p.atLengthUnit()) {
+			return clause, nil
+		}
+	}
+}
+
+// atLengthUnit reports whether the next tokens are the unit of a length, an
+// identifier followed by a colon.
+func (p *parser) atLengthUnit() bool {
+	return p.peek().Kind == TokenIdentifier && p.tokens[p.next+1].Kind == TokenColon
+}
+
+// Rule = Length | NamedRule .
+// Length = [ Unit ":" ] number [ "-" number ] .
+func (p *parser) parseRule() (*RuleNode, error) {
+	var unit *Token
+	if p.atLengthUnit() {
+		token := p.advance()
+		p.advance()
+		unit = &token
+	}
+
+	if p.at(TokenNumber, "length") {
+		min := p.advance()
+		rule := &RuleNode{Name: lengthRuleName, Args: []*ArgNode{{Value: min.Text, Span: min.Span}}, Span: min.Span}
+		if unit != nil {
+			rule.Unit = unit.Text
+			rule.Span.Start = unit.Span.Start
+		}
+
+		if p.accept(TokenDash, "'-'") {
+			max, err := p.expect(TokenNumber, "maximum length")
+			if err != nil {
+				return nil, err
+			}
+			rule.Args = append(rule.Args, &ArgNode{Value: max.Text, Span: max.Span})
+			rule.Span.End = max.Span.End
+		}
+		return rule, nil
+	}
+	if unit != nil {
+		return nil, p.unexpected()
+	}
+
+	if !p.at(TokenIdentifier, "rule") {
+		return nil, p.unexpected()
+	}
+	name := p.advance()
+	if name.Text == lengthRuleName {
+		// Lengths are only written as literals, so the AST tells them apart.
+		return nil, createUnexpectedTokenError(p.tag, name.Text, name.Span.Start)
+	}
+	rule := &RuleNode{Name: name.Text, Span: name.Span}
+
+	if !p.accept(TokenLParen, "'('") {
+		return rule, nil
+	}
+	if closer := p.peek(); p.accept(TokenRParen, "')'") {
+		rule.Span.End = closer.Span.End
+		return rule, nil
+	}
+	for {
+		arg, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		rule.Args = append(rule.Args, arg)
+
+		if p.accept(TokenComma, "','") {
+			continue
+		}
+		closer, err := p.expect(TokenRParen, "')'")
+		if err != nil {
+			return nil, err
+		}
+		rule.Span.End = closer.Span.End
+		return rule, nil
+	}
+}
+
+// argTokens are the tokens an unquoted argument can be made of.
+var argTokens = map[TokenKind]bool{
+	TokenIdentifier: true,
+	TokenNumber:     true,
+	TokenDash:       true,
+	TokenColon:      true,
+}
+
+// Argument = string | RawArgument .
+func (p *parser) parseArg() (*ArgNode, error) {
+	if token := p.peek(); token.Kind == TokenString {
+		p.advance()
+		return &ArgNode{Value: token.Text, Quoted: true, Span: token.Span}, nil
+	}
+
+	// The parts of a raw argument are written together: oneof(GBP EUR) is a
+	// missing comma, not the value "GBP EUR".
+	span := Span{Start: p.peek().Span.Start}
+	for argTokens[p.peek().Kind] && (span.End == 0 || p.peek().Span.Start == span.End) {
+		span.End = p.advance().Span.End
+	}
+	if span.End == 0 {
+		p.expected = append(p.expected, "argument")
+		return nil, p.unexpected()
+	}
+	return &ArgNode{Value: p.tag[span.Start:span.End], Span: span}, nil
+}
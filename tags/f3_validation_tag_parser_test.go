@@ -0,0 +1,146 @@
+package tags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseTagProducesExpectedAST(t *testing.T) {
+	tag := "[GB,PT:7-10 required | AU:since(2026-03-01),oneof('a b', c)]"
+
+	node, err := ParseTag(tag)
+
+	assert.Nil(t, err)
+	assert.Equal(t, &TagNode{
+		Source: tag,
+		Span:   Span{0, 60},
+		Clauses: []*ClauseNode{
+			{
+				Countries: []*CountryNode{{Code: "GB", Span: Span{1, 3}}, {Code: "PT", Span: Span{4, 6}}},
+				Rules: []*RuleNode{
+					{Name: lengthRuleName, Args: []*ArgNode{{Value: "7", Span: Span{7, 8}}, {Value: "10", Span: Span{9, 11}}}, Span: Span{7, 11}},
+					{Name: "required", Span: Span{12, 20}},
+				},
+				Span: Span{1, 20},
+			},
+			{
+				Countries: []*CountryNode{{Code: "AU", Span: Span{23, 25}}},
+				Rules: []*RuleNode{
+					{Name: "since", Args: []*ArgNode{{Value: "2026-03-01", Span: Span{32, 42}}}, Span: Span{26, 43}},
+					{Name: "oneof", Args: []*ArgNode{{Value: "a b", Quoted: true, Span: Span{50, 55}}, {Value: "c", Span: Span{57, 58}}}, Span: Span{44, 59}},
+				},
+				Span: Span{23, 59},
+			},
+		},
+	}, node)
+}
+
+func Test_ParseTagReadsSchemesOfCountries(t *testing.T) {
+	node, err := ParseTag("[GB/FPS, IE:6]")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*CountryNode{
+		{Code: "GB", Scheme: "FPS", Span: Span{1, 7}},
+		{Code: "IE", Span: Span{9, 11}},
+	}, node.Clauses[0].Countries)
+	assert.Equal(t, "GB/FPS", node.Clauses[0].Countries[0].Key())
+}
+
+func Test_ParseTagReadsDimensions(t *testing.T) {
+	node, err := ParseTag("[country=GB, scheme=FPS, currency=EUR:6]")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*CountryNode{
+		{Code: "GB", Span: Span{1, 11}},
+		{Scheme: "FPS", Span: Span{13, 23}},
+		{Dimension: "currency", Value: "EUR", Span: Span{25, 37}},
+	}, node.Clauses[0].Countries)
+	assert.Equal(t, "scheme=FPS", node.Clauses[0].Countries[1].Key())
+	assert.Equal(t, "currency=EUR", node.Clauses[0].Countries[2].Key())
+}
+
+func Test_ParseTagReadsUnitsOfLengths(t *testing.T) {
+	node, err := ParseTag("[GB:runes:1-35,required]")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*RuleNode{
+		{Name: lengthRuleName, Unit: "runes", Args: []*ArgNode{{Value: "1", Span: Span{10, 11}}, {Value: "35", Span: Span{12, 14}}}, Span: Span{4, 14}},
+		{Name: "required", Span: Span{15, 23}},
+	}, node.Clauses[0].Rules)
+}
+
+func Test_ParseTagReportsWhatTheGrammarExpected(t *testing.T) {
+	cases := []struct {
+		description          string
+		tag                  string
+		expectedCode         ErrorCode
+		expectedErrorMessage string
+	}{
+		{
+			description:          "missing opener",
+			tag:                  "GB:7]",
+			expectedCode:         CodeUnexpectedToken,
+			expectedErrorMessage: "unexpected token GB in position 0, expected '['",
+		},
+		{
+			description:          "missing rule after colon",
+			tag:                  "[GB:]",
+			expectedCode:         CodeUnexpectedSymbol,
+			expectedErrorMessage: "unexpected ] symbol in position 4, expected length or rule",
+		},
+		{
+			description:          "missing scheme",
+			tag:                  "[GB/:6]",
+			expectedCode:         CodeUnexpectedSymbol,
+			expectedErrorMessage: "unexpected : symbol in position 4, expected scheme",
+		},
+		{
+			description:          "missing maximum length",
+			tag:                  "[GB:7-]",
+			expectedCode:         CodeUnexpectedSymbol,
+			expectedErrorMessage: "unexpected ] symbol in position 6, expected maximum length",
+		},
+		{
+			description:          "unit without length",
+			tag:                  "[GB:runes:required]",
+			expectedCode:         CodeUnexpectedToken,
+			expectedErrorMessage: "unexpected token required in position 10, expected length",
+		},
+		{
+			description:          "unit after a rule without comma",
+			tag:                  "[GB:required runes:1-35]",
+			expectedCode:         CodeUnexpectedToken,
+			expectedErrorMessage: "unexpected token runes in position 13, expected '(', ',', '|' or ']'",
+		},
+		{
+			description:          "empty argument",
+			tag:                  "[GB:oneof(a,)]",
+			expectedCode:         CodeUnexpectedSymbol,
+			expectedErrorMessage: "unexpected ) symbol in position 12, expected argument",
+		},
+		{
+			description:          "unterminated arguments",
+			tag:                  "[GB:oneof(a",
+			expectedCode:         CodeUnterminatedTag,
+			expectedErrorMessage: "unexpected end of tag in position 11, expected ',' or ')'",
+		},
+		{
+			description:          "unterminated string",
+			tag:                  "[GB:oneof('a)]",
+			expectedCode:         CodeUnexpectedSymbol,
+			expectedErrorMessage: "unterminated string in position 10, expected ')' or argument",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			_, err := ParseTag(c.tag)
+
+			tagError, ok := err.(*TagError)
+			assert.True(t, ok)
+			assert.Equal(t, c.expectedCode, tagError.Code)
+			assert.Equal(t, c.expectedErrorMessage, tagError.Summary())
+		})
+	}
+}
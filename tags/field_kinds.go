@@ -0,0 +1,152 @@
+package tags
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// FieldKind is what the rules see of the type of a field. Strings take every
+// rule. Integers, unsigned integers, floats and exported time.Time fields only
+// take comparisons with fields of their kind, and fields of other types take
+// no rules.
+type FieldKind string
+
+const (
+	StringKind FieldKind = "string"
+	IntKind    FieldKind = "int"
+	UintKind   FieldKind = "uint"
+	FloatKind  FieldKind = "float"
+	TimeKind   FieldKind = "time.Time"
+	OtherKind  FieldKind = "other"
+	// UnknownKind is the kind of a field whose type a tool reading source
+	// cannot tell, e.g. a type declared in another file, which is not checked.
+	UnknownKind FieldKind = ""
+)
+
+// comparableKinds are the kinds comparisons compare, in the order errors list them.
+var comparableKinds = []FieldKind{StringKind, IntKind, UintKind, FloatKind, TimeKind}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// StructField is a field of a struct, as CheckStructFields sees it.
+type StructField struct {
+	Name string
+	Type string // as written in Go, e.g. int or time.Time
+	Kind FieldKind
+}
+
+// structFields returns the fields t declares, leaving out promoted fields.
+func structFields(t reflect.Type) []StructField {
+	fields := make([]StructField, 0, t.NumField())
+	for index := 0; index < t.NumField(); index++ {
+		field := t.Field(index)
+		kind := valueKind(field.Type)
+		if kind == TimeKind && !field.IsExported() {
+			// Reflection cannot read an unexported time.Time to compare it.
+			kind = OtherKind
+		}
+		fields = append(fields, StructField{Name: field.Name, Type: field.Type.String(), Kind: kind})
+	}
+	return fields
+}
+
+// valueKind returns the kind of the values of type t.
+func valueKind(t reflect.Type) FieldKind {
+	switch t.Kind() {
+	case reflect.String:
+		return StringKind
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return IntKind
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return UintKind
+	case reflect.Float32, reflect.Float64:
+		return FloatKind
+	}
+	if t == timeType {
+		return TimeKind
+	}
+	return OtherKind
+}
+
+// ruleLocation locates a rule in its tag, for the errors reported when the
+// matrix is built.
+type ruleLocation struct {
+	rule     string
+	tag      string
+	position int
+}
+
+func (location *ruleLocation) error(code ErrorCode, reason string) *TagError {
+	return &TagError{Code: code, Tag: location.tag, Position: location.position, Reason: reason, Expected: []string{string(StringKind)}}
+}
+
+// CheckStructFields checks the rules of the matrix of a struct against its
+// fields, which tags are compiled without. It reports the first rule, in field,
+// selector and period order, naming a field the struct does not declare or
+// comparing fields of different kinds or of a kind comparisons do not compare,
+// then the first normalizing a field that is not a string, then the first
+// checking such a field otherwise. Fields of UnknownKind are not checked.
+func CheckStructFields(structType string, fields []StructField, matrix ValidationMatrix) error {
+	byName := make(map[string]StructField, len(fields))
+	for _, field := range fields {
+		byName[field.Name] = field
+	}
+
+	for _, check := range []periodCheck{checkReferences, checkNormalizers, checkRules} {
+		for _, field := range fields {
+			if tagError := checkField(field, byName, matrix[field.Name], check); tagError != nil {
+				tagError.StructType, tagError.FieldName, tagError.FieldType = structType, field.Name, field.Type
+				return tagError
+			}
+		}
+	}
+	return nil
+}
+
+// periodCheck reports a rule of a period of field its kind does not allow.
+type periodCheck func(field StructField, fields map[string]StructField, period *CountryValidationInfo) *TagError
+
+func checkField(field StructField, fields map[string]StructField, validationInfos *CountriesValidationInfos, check periodCheck) *TagError {
+	if validationInfos == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(*validationInfos))
+	for key := range *validationInfos {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for period := (*validationInfos)[key]; period != nil; period = period.next {
+			if tagError := check(field, fields, period); tagError != nil {
+				return tagError
+			}
+		}
+	}
+	return nil
+}
+
+func checkReferences(field StructField, fields map[string]StructField, period *CountryValidationInfo) *TagError {
+	for _, reference := range period.periodReferences() {
+		if tagError := reference.check(field, fields); tagError != nil {
+			return tagError
+		}
+	}
+	return nil
+}
+
+func checkNormalizers(field StructField, _ map[string]StructField, period *CountryValidationInfo) *TagError {
+	if location := period.normalizedAt; location != nil && field.Kind != StringKind && field.Kind != UnknownKind {
+		return location.error(CodeUnnormalizableField, fmt.Sprintf("rule %s cannot normalize a field of type %s", location.rule, field.Type))
+	}
+	return nil
+}
+
+func checkRules(field StructField, _ map[string]StructField, period *CountryValidationInfo) *TagError {
+	if location := period.checkedAt; location != nil && field.Kind != StringKind && field.Kind != UnknownKind {
+		return location.error(CodeUnsupportedField, fmt.Sprintf("rule %s cannot check a field of type %s", location.rule, field.Type))
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package tags
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fieldReference is a sibling field named by a rule, e.g. BankIdCode in
+// required_if(BankIdCode) or StartDate in gtfield(StartDate). Tags are compiled without their struct, so the
+// name is checked when the matrix is built and resolved to the index of the
+// field when the plan is.
+type fieldReference struct {
+	rule  string
+	name  string
+	index int
+
+	// tag and position locate the name for the error of an unknown field.
+	tag      string
+	position int
+}
+
+func compileFieldReference(rule *RuleNode) (*fieldReference, error) {
+	if err := expectArgs(rule, 1, 1); err != nil {
+		return nil, err
+	}
+	return &fieldReference{rule: rule.Name, name: rule.Args[0].Value, position: rule.Args[0].Span.Start}, nil
+}
+
+// fieldComparison is a rule comparing a value with a sibling field of its
+// kind, e.g. gtfield(StartDate). Strings are compared byte by byte, numbers
+// and times by value.
+type fieldComparison struct {
+	operator    string // holding between the values, in generated code
+	verb        string // of the error message
+	description string
+	holds       func(comparison int) bool
+}
+
+var fieldComparisons = map[string]fieldComparison{
+	"eqfield": {operator: "==", verb: "equal", description: "equal to", holds: func(comparison int) bool { return comparison == 0 }},
+	"nefield": {operator: "!=", verb: "differ from", description: "different from", holds: func(comparison int) bool { return comparison != 0 }},
+	"gtfield": {operator: ">", verb: "be greater than", description: "greater than", holds: func(comparison int) bool { return comparison > 0 }},
+	"ltfield": {operator: "<", verb: "be less than", description: "less than", holds: func(comparison int) bool { return comparison < 0 }},
+}
+
+func compileFieldComparison(countryValidationInfo *CountryValidationInfo, rule *RuleNode) error {
+	reference, err := compileFieldReference(rule)
+	if err != nil {
+		return err
+	}
+	countryValidationInfo.comparisons = withComparison(countryValidationInfo.comparisons, reference)
+	return nil
+}
+
+// withComparison returns a copy of comparisons with comparison in place of the
+// one of the same rule, if any.
+func withComparison(comparisons []*fieldReference, comparison *fieldReference) []*fieldReference {
+	replaced := make([]*fieldReference, 0, len(comparisons)+1)
+	for _, existing := range comparisons {
+		if existing.rule != comparison.rule {
+			replaced = append(replaced, existing)
+		}
+	}
+	return append(replaced, comparison)
+}
+
+// fieldReferences returns the references of the rules, for each period.
+func (countryValidationInfo *CountryValidationInfo) fieldReferences() []*fieldReference {
+	var references []*fieldReference
+	for period := countryValidationInfo; period != nil; period = period.next {
+		references = append(references, period.periodReferences()...)
+	}
+	return references
+}
+
+// periodReferences returns the references of the rules of a single period.
+func (countryValidationInfo *CountryValidationInfo) periodReferences() []*fieldReference {
+	var references []*fieldReference
+	for _, reference := range []*fieldReference{countryValidationInfo.requiredIf, countryValidationInfo.requiredUnless} {
+		if reference != nil {
+			references = append(references, reference)
+		}
+	}
+	return append(references, countryValidationInfo.comparisons...)
+}
+
+// check reports a reference of a rule of field naming a field the struct does
+// not declare, or comparing fields that are not of the same comparable kind.
+func (reference *fieldReference) check(field StructField, fields map[string]StructField) *TagError {
+	tagError := &TagError{Tag: reference.tag, Position: reference.position}
+	referenced, ok := fields[reference.name]
+	if !ok {
+		tagError.Code = CodeUnknownField
+		tagError.Reason = fmt.Sprintf("rule %s refers to unknown field %s", reference.rule, reference.name)
+		return tagError
+	}
+	if _, compares := fieldComparisons[reference.rule]; !compares || field.Kind == UnknownKind || referenced.Kind == UnknownKind {
+		return nil
+	}
+
+	tagError.Code = CodeIncomparableField
+	switch {
+	case !isComparableKind(field.Kind):
+		tagError.Reason = fmt.Sprintf("rule %s cannot compare a field of type %s", reference.rule, field.Type)
+		for _, kind := range comparableKinds {
+			tagError.Expected = append(tagError.Expected, string(kind))
+		}
+	case referenced.Kind != field.Kind:
+		tagError.Reason = fmt.Sprintf("rule %s cannot compare with field %s of type %s", reference.rule, reference.name, referenced.Type)
+		tagError.Expected = []string{string(field.Kind)}
+	default:
+		return nil
+	}
+	return tagError
+}
+
+func isComparableKind(kind FieldKind) bool {
+	for _, comparable := range comparableKinds {
+		if kind == comparable {
+			return true
+		}
+	}
+	return false
+}
+
+// compareFields compares the value of a field with that of the field of its
+// kind a comparison names, strings once normalized. Empty strings and zero
+// times are not compared, which is left to required.
+func compareFields(field, other reflect.Value, normalizers []string) (comparison int, compared bool) {
+	switch valueKind(field.Type()) {
+	case StringKind:
+		value, otherValue := ApplyNormalizers(field.String(), normalizers...), ApplyNormalizers(other.String(), normalizers...)
+		return strings.Compare(value, otherValue), value != "" && otherValue != ""
+	case IntKind:
+		return cmp.Compare(field.Int(), other.Int()), true
+	case UintKind:
+		return cmp.Compare(field.Uint(), other.Uint()), true
+	case FloatKind:
+		return cmp.Compare(field.Float(), other.Float()), true
+	default:
+		value, otherValue := field.Interface().(time.Time), other.Interface().(time.Time)
+		return value.Compare(otherValue), !value.IsZero() && !otherValue.IsZero()
+	}
+}
+
+// formatField prints the value of a field as the errors of comparisons name
+// it, numbers as fmt prints the widest type of their kind in generated code.
+func formatField(field reflect.Value, normalizers []string) string {
+	switch valueKind(field.Type()) {
+	case StringKind:
+		return ApplyNormalizers(field.String(), normalizers...)
+	case IntKind:
+		return strconv.FormatInt(field.Int(), 10)
+	case UintKind:
+		return strconv.FormatUint(field.Uint(), 10)
+	case FloatKind:
+		return strconv.FormatFloat(field.Float(), 'g', -1, 64)
+	default:
+		return field.Interface().(time.Time).Format(time.RFC3339Nano)
+	}
+}
+
+// resolve returns a copy of the reference with the index of its field in t,
+// which CheckStructFields has found.
+func (reference *fieldReference) resolve(t reflect.Type) *fieldReference {
+	if reference == nil {
+		return nil
+	}
+	resolved := *reference
+	field, _ := t.FieldByName(reference.name)
+	resolved.index = field.Index[0]
+	return &resolved
+}
+
+// resolveAll returns copies of the references resolved in t.
+func resolveAll(references []*fieldReference, t reflect.Type) []*fieldReference {
+	if len(references) == 0 {
+		return nil
+	}
+	resolved := make([]*fieldReference, 0, len(references))
+	for _, reference := range references {
+		resolved = append(resolved, reference.resolve(t))
+	}
+	return resolved
+}
+
+// IsSet reports whether value is not the zero value of its type, as
+// required_if and required_unless tell whether the field they name is set.
+func IsSet[T comparable](value T) bool {
+	var zero T
+	return value != zero
+}
@@ -0,0 +1,303 @@
+package tags
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ukAccount struct {
+	BankIdCode    string
+	BankId        string `f3_validate:"[GB:required_if(BankIdCode),6-11]"`
+	AccountNumber string
+	IBAN          string `f3_validate:"[GB:nospace,required_unless(AccountNumber) | IE:required]"`
+	Amount        int
+}
+
+func Test_ValidateChecksConditionalRules(t *testing.T) {
+	cases := []struct {
+		description              string
+		account                  ukAccount
+		expectedValidationErrors []string
+	}{
+		{
+			description:              "conditions met",
+			account:                  ukAccount{BankIdCode: "GBDSC", BankId: "601613", IBAN: "GB29NWBK60161331926819"},
+			expectedValidationErrors: nil,
+		},
+		{
+			description:              "conditions not applying",
+			account:                  ukAccount{BankId: "601613", AccountNumber: "31926819"},
+			expectedValidationErrors: nil,
+		},
+		{
+			description: "required if set",
+			account:     ukAccount{BankIdCode: "GBDSC", AccountNumber: "31926819"},
+			expectedValidationErrors: []string{
+				"field BankId is required when country is GB and BankIdCode is set",
+			},
+		},
+		{
+			description: "required unless set, once normalized",
+			account:     ukAccount{BankId: "601613", IBAN: "  "},
+			expectedValidationErrors: []string{
+				"field IBAN is required when country is GB and AccountNumber is not set",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			validationErrors, err := Validate(&c.account, "GB")
+
+			assert.Nil(t, err)
+			assert.Equal(t, c.expectedValidationErrors, validationErrors)
+		})
+	}
+}
+
+type misspelledReference struct {
+	BankId string `f3_validate:"[GB:6 | IE:required_if(BankCode)]"`
+}
+
+type promotedReference struct {
+	ukAccount
+	Reference string `f3_validate:"[GB:required_unless(BankIdCode)]"`
+}
+
+func Test_CreateValidationMatrixRejectsUnknownFields(t *testing.T) {
+	cases := []struct {
+		description   string
+		value         interface{}
+		expectedError string
+	}{
+		{
+			description:   "unknown field",
+			value:         misspelledReference{},
+			expectedError: "field tags.misspelledReference.BankId (string): rule required_if refers to unknown field BankCode in position 23\n\t[GB:6 | IE:required_if(BankCode)]\n\t                       ^",
+		},
+		{
+			description:   "promoted field",
+			value:         promotedReference{},
+			expectedError: "field tags.promotedReference.Reference (string): rule required_unless refers to unknown field BankIdCode in position 20\n\t[GB:required_unless(BankIdCode)]\n\t                    ^",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			_, err := CreateValidationMatrix(c.value)
+
+			assert.NotNil(t, err)
+			assert.Equal(t, CodeUnknownField, err.(*TagError).Code)
+			assert.Equal(t, c.expectedError, err.Error())
+		})
+	}
+}
+
+func Test_ValidatorRejectsRuleFilesNamingUnknownFields(t *testing.T) {
+	rules, err := ParseRuleFile([]byte("types:\n  tags.ukAccount:\n    BankId:\n      IE: required_unless(SortCode)\n"), YAMLDocument)
+	assert.Nil(t, err)
+
+	_, err = mustValidator(t, WithRuleSet(rules)).Validate(context.Background(), ukAccount{}, Dimensions{CountryDimension: "GB"})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "rule required_unless refers to unknown field SortCode in position 20", err.(*TagError).Summary())
+}
+
+type standingOrder struct {
+	Name             string
+	AlternativeNames string `f3_validate:"[GB:nefield(Name)]"`
+	IBAN             string
+	ConfirmIBAN      string `f3_validate:"[GB:nospace,upper,eqfield(IBAN)]"`
+	StartDate        string
+	EndDate          string `f3_validate:"[GB:gtfield(StartDate) | IE:ltfield(StartDate),gtfield(StartDate)]"`
+}
+
+func Test_ValidateComparesFields(t *testing.T) {
+	cases := []struct {
+		description              string
+		order                    standingOrder
+		country                  string
+		expectedValidationErrors []string
+	}{
+		{
+			description:              "comparisons holding",
+			order:                    standingOrder{Name: "Jane Doe", AlternativeNames: "J Doe", IBAN: "GB29NWBK60161331926819", ConfirmIBAN: "gb29 nwbk 6016 1331 9268 19", StartDate: "2026-03-01", EndDate: "2027-03-01"},
+			country:                  "GB",
+			expectedValidationErrors: nil,
+		},
+		{
+			description:              "empty values left to required",
+			order:                    standingOrder{Name: "Jane Doe", IBAN: "GB29NWBK60161331926819", EndDate: "2027-03-01"},
+			country:                  "GB",
+			expectedValidationErrors: nil,
+		},
+		{
+			description: "comparisons failing",
+			order:       standingOrder{Name: "Jane Doe", AlternativeNames: "Jane Doe", IBAN: "GB29NWBK60161331926819", ConfirmIBAN: "GB29 NWBK 6016 1331 9268 18", StartDate: "2026-03-01", EndDate: "2026-03-01"},
+			country:     "GB",
+			expectedValidationErrors: []string{
+				"field AlternativeNames must differ from field Name when country is GB but found Jane Doe and Jane Doe",
+				"field ConfirmIBAN must equal field IBAN when country is GB but found GB29NWBK60161331926818 and GB29NWBK60161331926819",
+				"field EndDate must be greater than field StartDate when country is GB but found 2026-03-01 and 2026-03-01",
+			},
+		},
+		{
+			description: "contradicting comparisons",
+			order:       standingOrder{StartDate: "2026-03-01", EndDate: "2027-03-01"},
+			country:     "IE",
+			expectedValidationErrors: []string{
+				"field EndDate must be less than field StartDate when country is IE but found 2027-03-01 and 2026-03-01",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			validationErrors, err := Validate(&c.order, c.country)
+
+			assert.Nil(t, err)
+			assert.Equal(t, c.expectedValidationErrors, validationErrors)
+		})
+	}
+}
+
+type incomparableField struct {
+	Amount    int
+	Reference string `f3_validate:"[GB:1-18 | IE:gtfield(Amount)]"`
+}
+
+func Test_CreateValidationMatrixRejectsIncomparableFields(t *testing.T) {
+	_, err := CreateValidationMatrix(incomparableField{})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, CodeIncomparableField, err.(*TagError).Code)
+	assert.Equal(t, "field tags.incomparableField.Reference (string): rule gtfield cannot compare with field Amount of type int in position 22, expected string\n\t[GB:1-18 | IE:gtfield(Amount)]\n\t                      ^", err.Error())
+}
+
+type installmentPlan struct {
+	MinAmount   int64
+	MaxAmount   int64     `f3_validate:"[GB:gtfield(MinAmount)]"`
+	First       uint8     `f3_validate:"[GB:ltfield(Count)]"`
+	Count       uint8     `f3_validate:"[GB:nefield(First)]"`
+	Rate        float64   `f3_validate:"[GB:eqfield(AgreedRate)]"`
+	AgreedRate  float64   `f3_validate:"[GB:since(2026-01-01)]"`
+	StartDate   time.Time `f3_validate:"[GB:ltfield(EndDate)]"`
+	EndDate     time.Time
+	Description string `f3_validate:"[GB:1-35]"`
+}
+
+func Test_ValidateComparesFieldsOfOtherKindsByValue(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	cases := []struct {
+		description              string
+		plan                     installmentPlan
+		expectedValidationErrors []string
+	}{
+		{
+			description: "holding comparisons",
+			plan:        installmentPlan{MinAmount: 9, MaxAmount: 10, First: 1, Count: 12, Rate: 0.5, AgreedRate: 0.5, StartDate: start, EndDate: start.AddDate(1, 0, 0)},
+		},
+		{
+			description: "zero times are not compared",
+			plan:        installmentPlan{MinAmount: 9, MaxAmount: 10, First: 1, Count: 12, StartDate: start},
+		},
+		{
+			description: "failing comparisons",
+			plan:        installmentPlan{MinAmount: 10, MaxAmount: 9, First: 12, Count: 12, Rate: 0.25, AgreedRate: 0.5, StartDate: start, EndDate: start},
+			expectedValidationErrors: []string{
+				"field MaxAmount must be greater than field MinAmount when country is GB but found 9 and 10",
+				"field First must be less than field Count when country is GB but found 12 and 12",
+				"field Count must differ from field First when country is GB but found 12 and 12",
+				"field Rate must equal field AgreedRate when country is GB but found 0.25 and 0.5",
+				"field StartDate must be less than field EndDate when country is GB but found 2026-03-01T09:30:00Z and 2026-03-01T09:30:00Z",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			validationErrors, err := Validate(&c.plan, "GB")
+
+			assert.Nil(t, err)
+			assert.Equal(t, c.expectedValidationErrors, validationErrors)
+		})
+	}
+}
+
+func Test_CreateValidationMatrixRejectsComparisonsOfDifferentKinds(t *testing.T) {
+	type installments struct {
+		First int32
+		Last  uint `f3_validate:"[GB:gtfield(First)]"`
+	}
+	type mandate struct {
+		Signed  string
+		Expires time.Time `f3_validate:"[GB:gtfield(Signed)]"`
+	}
+	type instruction struct {
+		Urgent  bool
+		Pending bool `f3_validate:"[GB:nefield(Urgent)]"`
+	}
+	type signature struct {
+		signed  time.Time
+		Expires time.Time `f3_validate:"[GB:gtfield(signed)]"`
+	}
+	cases := []struct {
+		description string
+		value       interface{}
+		expected    string
+	}{
+		{
+			description: "integers of different signs",
+			value:       installments{},
+			expected:    "rule gtfield cannot compare with field First of type int32 in position 12, expected uint",
+		},
+		{
+			description: "time with string",
+			value:       mandate{},
+			expected:    "rule gtfield cannot compare with field Signed of type string in position 12, expected time.Time",
+		},
+		{
+			description: "booleans",
+			value:       instruction{},
+			expected:    "rule nefield cannot compare a field of type bool in position 12, expected string, int, uint, float or time.Time",
+		},
+		{
+			description: "unexported time",
+			value:       signature{},
+			expected:    "rule gtfield cannot compare with field signed of type time.Time in position 12, expected time.Time",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			_, err := CreateValidationMatrix(c.value)
+
+			assert.NotNil(t, err)
+			assert.Equal(t, CodeIncomparableField, err.(*TagError).Code)
+			assert.Equal(t, c.expected, err.(*TagError).Summary())
+		})
+	}
+}
+
+func Test_MergeReplacesComparisonsOfTheSameRule(t *testing.T) {
+	base, err := CompileCountriesValidationInfos("[GB:eqfield(IBAN),gtfield(StartDate)]")
+	assert.Nil(t, err)
+	override, err := CompileCountriesValidationInfos("[GB:gtfield(CreatedDate),ltfield(EndDate)]")
+	assert.Nil(t, err)
+
+	merged := base["GB"].merge(override["GB"])
+
+	assert.Equal(t, "eqfield(IBAN),gtfield(CreatedDate),ltfield(EndDate)", merged.String())
+	assert.Equal(t, "eqfield(IBAN),gtfield(StartDate)", base["GB"].String())
+}
+
+func Test_IsSet(t *testing.T) {
+	assert.True(t, IsSet("GBDSC"))
+	assert.False(t, IsSet(""))
+	assert.True(t, IsSet(42))
+	assert.False(t, IsSet(0))
+	assert.False(t, IsSet[*ukAccount](nil))
+}
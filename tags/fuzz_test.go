@@ -0,0 +1,123 @@
+package tags
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Run with go test -run '^$' -fuzz FuzzCompileCountriesValidationInfos ./tags.
+// Inputs that fail are saved under testdata/fuzz and replayed by go test.
+
+// addFuzzSeeds seeds a fuzz target with the tags of the table tests and of the
+// conformance suite.
+func addFuzzSeeds(f *testing.F) {
+	for _, c := range compileCases {
+		f.Add(c.validationStr)
+	}
+	for _, c := range invalidRuleArgumentCases {
+		f.Add(c.validationStr)
+	}
+	for _, tag := range roundTripTags {
+		f.Add(tag)
+	}
+	paths, err := filepath.Glob("testdata/conformance/*/*.tag")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, path := range paths {
+		tag, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(string(tag))
+	}
+}
+
+func FuzzParseTag(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, tag string) {
+		node, err := ParseTag(tag)
+		if err != nil {
+			tagError, ok := err.(*TagError)
+			if !ok {
+				t.Fatalf("ParseTag(%q) returned %T, not a *TagError", tag, err)
+			}
+			if tagError.Position < 0 || tagError.Position > len(tag) {
+				t.Fatalf("ParseTag(%q) reported position %d out of the tag", tag, tagError.Position)
+			}
+			return
+		}
+
+		formatted := FormatTag(node)
+		reparsed, err := ParseTag(formatted)
+		if err != nil {
+			t.Fatalf("FormatTag(%q) = %q, which does not parse: %v", tag, formatted, err)
+		}
+		if reformatted := FormatTag(reparsed); reformatted != formatted {
+			t.Fatalf("FormatTag(%q) = %q, but formatting it again gives %q", tag, formatted, reformatted)
+		}
+	})
+}
+
+func FuzzCompileCountriesValidationInfos(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, tag string) {
+		compiled, err := CompileCountriesValidationInfos(tag)
+		if err != nil {
+			tagError, ok := err.(*TagError)
+			if !ok {
+				t.Fatalf("CompileCountriesValidationInfos(%q) returned %T, not a *TagError", tag, err)
+			}
+			if tagError.Position < 0 || tagError.Position > len(tag) {
+				t.Fatalf("CompileCountriesValidationInfos(%q) reported position %d out of the tag", tag, tagError.Position)
+			}
+			return
+		}
+
+		formatted, err := Format(tag)
+		if err != nil {
+			t.Fatalf("Format(%q) failed on a tag that compiles: %v", tag, err)
+		}
+		if reformatted, err := Format(formatted); err != nil || reformatted != formatted {
+			t.Fatalf("Format(%q) = %q, but formatting it again gives %q, %v", tag, formatted, reformatted, err)
+		}
+
+		printed := compiled.String()
+		if node, _ := ParseTag(tag); isSinglePeriod(node) && formatted != printed {
+			t.Fatalf("Format(%q) = %q, but its rules print as %q", tag, formatted, printed)
+		}
+		if formattedCompiled, err := CompileCountriesValidationInfos(formatted); err != nil || formattedCompiled.String() != printed {
+			t.Fatalf("Format(%q) = %q, which does not compile to the rules %q of the tag: %v", tag, formatted, printed, err)
+		}
+		recompiled, err := CompileCountriesValidationInfos(printed)
+		if err != nil {
+			t.Fatalf("rules of %q print as %q, which does not compile: %v", tag, printed, err)
+		}
+		if reprinted := recompiled.String(); reprinted != printed {
+			t.Fatalf("rules of %q print as %q, but compiling and printing them again gives %q", tag, printed, reprinted)
+		}
+	})
+}
+
+// isSinglePeriod reports whether each clause of a tag has a single selector
+// and rules that are neither dated nor overridden by a later rule of the same
+// name, so that Format prints it as its compiled rules print.
+func isSinglePeriod(node *TagNode) bool {
+	for _, clause := range node.Clauses {
+		if len(clause.Countries) != 1 {
+			return false
+		}
+		seen := make(map[string]bool)
+		for _, rule := range clause.Rules {
+			if _, normalizes := normalizers[rule.Name]; normalizes {
+				continue
+			}
+			if seen[rule.Name] || rule.Name == "since" || rule.Name == "until" {
+				return false
+			}
+			seen[rule.Name] = true
+		}
+	}
+	return true
+}
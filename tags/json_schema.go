@@ -0,0 +1,213 @@
+package tags
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// JSONSchemaDialect is the JSON Schema draft the generated schemas declare.
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is the subset of JSON Schema, and of the OpenAPI keywords added to
+// it, that f3_validate rules map to. It is meant to be encoded with
+// encoding/json or gopkg.in/yaml.v3.
+type JSONSchema struct {
+	Schema        string                 `json:"$schema,omitempty" yaml:"$schema,omitempty"`
+	Ref           string                 `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Title         string                 `json:"title,omitempty" yaml:"title,omitempty"`
+	Type          string                 `json:"type,omitempty" yaml:"type,omitempty"`
+	Format        string                 `json:"format,omitempty" yaml:"format,omitempty"`
+	Properties    map[string]*JSONSchema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required      []string               `json:"required,omitempty" yaml:"required,omitempty"`
+	MinLength     *int                   `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	MaxLength     *int                   `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	Pattern       string                 `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Enum          []string               `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Const         *string                `json:"const,omitempty" yaml:"const,omitempty"`
+	AllOf         []*JSONSchema          `json:"allOf,omitempty" yaml:"allOf,omitempty"`
+	AnyOf         []*JSONSchema          `json:"anyOf,omitempty" yaml:"anyOf,omitempty"`
+	OneOf         []*JSONSchema          `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+	If            *JSONSchema            `json:"if,omitempty" yaml:"if,omitempty"`
+	Then          *JSONSchema            `json:"then,omitempty" yaml:"then,omitempty"`
+	Discriminator *OpenAPIDiscriminator  `json:"discriminator,omitempty" yaml:"discriminator,omitempty"`
+	CountryRules  map[string]string      `json:"x-f3-country-rules,omitempty" yaml:"x-f3-country-rules,omitempty"`
+}
+
+// jsonSchemaField is a tagged field with the name encoding/json gives it.
+type jsonSchemaField struct {
+	fieldName    string
+	propertyName string
+	kind         FieldKind
+}
+
+// property returns the schema of the values of the field as encoding/json
+// encodes them, numbers as numbers and times as date-time strings.
+func (field jsonSchemaField) property() *JSONSchema {
+	switch field.kind {
+	case IntKind, UintKind:
+		return &JSONSchema{Type: "integer"}
+	case FloatKind:
+		return &JSONSchema{Type: "number"}
+	case TimeKind:
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	}
+	return &JSONSchema{Type: "string"}
+}
+
+// GenerateJSONSchema returns the schema of a struct, or pointer to struct, for
+// the rules of a country. Properties are named as encoding/json names them and
+// hold the tagged fields, typed as encoding/json encodes them: length maps to
+// minLength and maxLength as far as they agree with its unit, required to
+// required and a minLength of at least 1, pattern to pattern and oneof to enum,
+// optional properties also allowing "". Rules with effective dates are those in
+// effect at asOf, so that the schema only depends on the arguments. country may
+// be any selector of the tag grammar, e.g. GB/FPS or GB,currency=GBP, each
+// field getting the rules Validate would apply to it.
+func GenerateJSONSchema(i interface{}, country string, asOf time.Time) (*JSONSchema, error) {
+	t, fields, matrix, err := jsonSchemaFields(i)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := countryJSONSchema(fields, matrix, country, asOf)
+	schema.Schema = JSONSchemaDialect
+	schema.Title = t.Name()
+	return schema, nil
+}
+
+func countryJSONSchema(fields []jsonSchemaField, matrix ValidationMatrix, key string, asOf time.Time) *JSONSchema {
+	dimensions := keyDimensions(key)
+	schema := &JSONSchema{Type: "object", Properties: make(map[string]*JSONSchema)}
+	for _, field := range fields {
+		property := field.property()
+		if countryValidationInfo := matrix[field.fieldName].rulesFor(dimensions, asOf); countryValidationInfo != nil {
+			if countryValidationInfo.applyToJSONSchema(property) {
+				schema.Required = append(schema.Required, field.propertyName)
+			}
+		}
+		schema.Properties[field.propertyName] = property
+	}
+	return schema
+}
+
+// GenerateJSONSchemaByCountry returns a single schema for every country of the
+// struct, with an if/then branch per country keyed on the value of countryProperty,
+// and the rules in effect at asOf. Rules of payment schemes and other dimensions
+// are left out.
+func GenerateJSONSchemaByCountry(i interface{}, countryProperty string, asOf time.Time) (*JSONSchema, error) {
+	t, fields, matrix, err := jsonSchemaFields(i)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &JSONSchema{Schema: JSONSchemaDialect, Title: t.Name(), Type: "object", Properties: map[string]*JSONSchema{countryProperty: {Type: "string"}}}
+	for _, field := range fields {
+		schema.Properties[field.propertyName] = field.property()
+	}
+
+	for _, country := range schemelessCountries(matrix) {
+		country := country
+		then := &JSONSchema{Properties: make(map[string]*JSONSchema)}
+		for _, field := range fields {
+			if countryValidationInfo := (*matrix[field.fieldName])[country].at(asOf); countryValidationInfo != nil {
+				property := &JSONSchema{}
+				if countryValidationInfo.applyToJSONSchema(property) {
+					then.Required = append(then.Required, field.propertyName)
+				}
+				then.Properties[field.propertyName] = property
+			}
+		}
+
+		schema.AllOf = append(schema.AllOf, &JSONSchema{
+			If:   &JSONSchema{Properties: map[string]*JSONSchema{countryProperty: {Const: &country}}, Required: []string{countryProperty}},
+			Then: then,
+		})
+	}
+	return schema, nil
+}
+
+// applyToJSONSchema sets the keywords of the rules on the schema of a property
+// and reports whether the property is required. minLength and maxLength count
+// code points, so lengths in runes map to both, and of those in other units
+// only the bound a value within the length also meets in code points: the
+// maximum of bytes, lengths without a unit included, and the minimum of
+// graphemes. As Validate leaves empty values to required, the minLength,
+// pattern and enum of an optional property are an alternative to "".
+func (countryValidationInfo *CountryValidationInfo) applyToJSONSchema(property *JSONSchema) bool {
+	unit := countryValidationInfo.lengthUnit
+	if unit == "" {
+		unit = Bytes
+	}
+
+	if countryValidationInfo.maxLen > 0 && (unit == Runes || unit == Bytes) {
+		maxLen := countryValidationInfo.maxLen
+		property.MaxLength = &maxLen
+	}
+
+	value := property
+	if !countryValidationInfo.required {
+		value = &JSONSchema{}
+	}
+	minLen := 0
+	if unit == Runes || unit == Graphemes {
+		minLen = countryValidationInfo.minLen
+	}
+	if countryValidationInfo.required && minLen == 0 {
+		minLen = 1
+	}
+	if minLen > 0 {
+		value.MinLength = &minLen
+	}
+	if countryValidationInfo.pattern != nil {
+		value.Pattern = countryValidationInfo.pattern.String()
+	}
+	value.Enum = countryValidationInfo.oneOf
+	if value != property && (value.MinLength != nil || value.Pattern != "" || len(value.Enum) > 0) {
+		empty := ""
+		property.AnyOf = []*JSONSchema{{Const: &empty}, value}
+	}
+	return countryValidationInfo.required
+}
+
+func jsonSchemaFields(i interface{}) (reflect.Type, []jsonSchemaField, ValidationMatrix, error) {
+	t := reflect.TypeOf(i)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	matrix, err := CreateValidationMatrix(reflect.Zero(t).Interface())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var fields []jsonSchemaField
+	kinds := structFields(t)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if _, hasRules := matrix[field.Name]; !hasRules {
+			continue
+		}
+		propertyName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if propertyName == "-" {
+			continue
+		}
+		if propertyName == "" {
+			propertyName = field.Name
+		}
+		fields = append(fields, jsonSchemaField{fieldName: field.Name, propertyName: propertyName, kind: kinds[i].Kind})
+	}
+	return t, fields, matrix, nil
+}
+
+// schemelessCountries returns the countries of the matrix, sorted, leaving out
+// those narrowed to a payment scheme or another dimension.
+func schemelessCountries(matrix ValidationMatrix) []string {
+	var countries []string
+	for _, key := range matrixCountries([]*MatrixTable{{Matrix: matrix}}) {
+		if dimensions := keyDimensions(key); len(dimensions) == 1 && dimensions[CountryDimension] != "" {
+			countries = append(countries, key)
+		}
+	}
+	return countries
+}
@@ -0,0 +1,218 @@
+package tags
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type transfer struct {
+	Country   string `json:"country"`
+	Currency  string `json:"currency" f3_validate:"[GB:oneof(GBP, EUR),required | PT:oneof(EUR)]"`
+	Reference string `json:"reference,omitempty" f3_validate:"[GB:1-18,pattern('^[A-Z0-9 ]+$') | PT:required]"`
+	Internal  string `json:"-" f3_validate:"[GB:required]"`
+}
+
+// schemaAsOf is the time of the rules the schemas of the tests are generated with.
+var schemaAsOf = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func Test_GenerateJSONSchemaMapsRulesOfCountry(t *testing.T) {
+	cases := []struct {
+		description    string
+		value          interface{}
+		country        string
+		expectedSchema string
+	}{
+		{
+			description: "rules of the country",
+			value:       transfer{},
+			country:     "GB",
+			expectedSchema: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"title": "transfer",
+				"type": "object",
+				"properties": {
+					"currency": {"type": "string", "minLength": 1, "enum": ["GBP", "EUR"]},
+					"reference": {"type": "string", "maxLength": 18, "anyOf": [{"const": ""}, {"pattern": "^[A-Z0-9 ]+$"}]}
+				},
+				"required": ["currency"]
+			}`,
+		},
+		{
+			description: "fields without json names",
+			value:       &account{},
+			country:     "PT",
+			expectedSchema: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"title": "account",
+				"type": "object",
+				"properties": {
+					"BankId": {"type": "string", "maxLength": 5},
+					"IBAN": {"type": "string", "minLength": 1, "maxLength": 9}
+				},
+				"required": ["IBAN"]
+			}`,
+		},
+		{
+			description: "country without rules",
+			value:       account{},
+			country:     "IE",
+			expectedSchema: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"title": "account",
+				"type": "object",
+				"properties": {
+					"BankId": {"type": "string"},
+					"IBAN": {"type": "string"}
+				}
+			}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			schema, err := GenerateJSONSchema(c.value, c.country, schemaAsOf)
+			assert.Nil(t, err)
+
+			encoded, err := json.Marshal(schema)
+			assert.Nil(t, err)
+			assert.JSONEq(t, c.expectedSchema, string(encoded))
+		})
+	}
+}
+
+func Test_GenerateJSONSchemaByCountryBranchesOnCountryProperty(t *testing.T) {
+	schema, err := GenerateJSONSchemaByCountry(transfer{}, "country", schemaAsOf)
+	assert.Nil(t, err)
+
+	encoded, err := json.Marshal(schema)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "transfer",
+		"type": "object",
+		"properties": {
+			"country": {"type": "string"},
+			"currency": {"type": "string"},
+			"reference": {"type": "string"}
+		},
+		"allOf": [
+			{
+				"if": {"properties": {"country": {"const": "GB"}}, "required": ["country"]},
+				"then": {
+					"properties": {
+						"currency": {"minLength": 1, "enum": ["GBP", "EUR"]},
+						"reference": {"maxLength": 18, "anyOf": [{"const": ""}, {"pattern": "^[A-Z0-9 ]+$"}]}
+					},
+					"required": ["currency"]
+				}
+			},
+			{
+				"if": {"properties": {"country": {"const": "PT"}}, "required": ["country"]},
+				"then": {
+					"properties": {
+						"currency": {"anyOf": [{"const": ""}, {"enum": ["EUR"]}]},
+						"reference": {"minLength": 1}
+					},
+					"required": ["reference"]
+				}
+			}
+		]
+	}`, string(encoded))
+}
+
+type payeeName struct {
+	Name      string `f3_validate:"[GB:2-35]"`
+	Runes     string `f3_validate:"[GB:runes:2-35]"`
+	Graphemes string `f3_validate:"[GB:graphemes:2-35]"`
+	Bytes     string `f3_validate:"[GB:bytes:2-35,required]"`
+}
+
+func Test_GenerateJSONSchemaMapsLengthsAsFarAsTheirUnitAllows(t *testing.T) {
+	schema, err := GenerateJSONSchema(payeeName{}, "GB", schemaAsOf)
+	assert.Nil(t, err)
+
+	encoded, err := json.Marshal(schema.Properties)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"Name": {"type": "string", "maxLength": 35},
+		"Runes": {"type": "string", "maxLength": 35, "anyOf": [{"const": ""}, {"minLength": 2}]},
+		"Graphemes": {"type": "string", "anyOf": [{"const": ""}, {"minLength": 2}]},
+		"Bytes": {"type": "string", "minLength": 1, "maxLength": 35}
+	}`, string(encoded))
+}
+
+func Test_GenerateJSONSchemaReturnsTagErrors(t *testing.T) {
+	_, err := GenerateJSONSchema(wrongAccountStruct{}, "GB", schemaAsOf)
+
+	assert.NotNil(t, err)
+}
+
+func Test_GenerateJSONSchemaOfSchemeFallsBackToCountry(t *testing.T) {
+	schema, err := GenerateJSONSchema(payee{}, "GB/FPS", schemaAsOf)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 6, *schema.Properties["SortCode"].MaxLength)
+	assert.Equal(t, 8, *schema.Properties["AccountNumber"].MaxLength)
+	assert.Equal(t, []string{"AccountNumber"}, schema.Required)
+
+	byCountry, err := GenerateJSONSchemaByCountry(payee{}, "country", schemaAsOf)
+	assert.Nil(t, err)
+	assert.Len(t, byCountry.AllOf, 1, "schemes are left out")
+}
+
+func Test_GenerateJSONSchemaAllowsEmptyOptionalValues(t *testing.T) {
+	type currencyCode struct {
+		Code string `f3_validate:"[GB:runes:3-5,pattern('^[A-Z]+$'),oneof(AAA,BBB)]"`
+	}
+
+	validationErrors, err := Validate(currencyCode{}, "GB")
+	assert.Nil(t, err)
+	assert.Nil(t, validationErrors)
+
+	schema, err := GenerateJSONSchema(currencyCode{}, "GB", schemaAsOf)
+	assert.Nil(t, err)
+
+	encoded, err := json.Marshal(schema.Properties)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"Code": {"type": "string", "maxLength": 5, "anyOf": [{"const": ""}, {"minLength": 3, "pattern": "^[A-Z]+$", "enum": ["AAA", "BBB"]}]}
+	}`, string(encoded))
+}
+
+func Test_GenerateJSONSchemaTypesComparedFieldsAsTheyAreEncoded(t *testing.T) {
+	schema, err := GenerateJSONSchema(installmentPlan{}, "GB", schemaAsOf)
+	assert.Nil(t, err)
+
+	encoded, err := json.Marshal(schema.Properties)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"MaxAmount": {"type": "integer"},
+		"First": {"type": "integer"},
+		"Count": {"type": "integer"},
+		"Rate": {"type": "number"},
+		"AgreedRate": {"type": "number"},
+		"StartDate": {"type": "string", "format": "date-time"},
+		"Description": {"type": "string", "maxLength": 35}
+	}`, string(encoded))
+}
+
+func Test_GenerateJSONSchemaUsesRulesInEffectAtAsOf(t *testing.T) {
+	type reference struct {
+		Reference string `f3_validate:"[GB:1-18,until(2026-03-01) | GB:1-35,since(2026-03-01)]"`
+	}
+
+	before, err := GenerateJSONSchema(reference{}, "GB", schemaAsOf.Add(-time.Nanosecond))
+	assert.Nil(t, err)
+	assert.Equal(t, 18, *before.Properties["Reference"].MaxLength)
+
+	after, err := GenerateJSONSchema(reference{}, "GB", schemaAsOf)
+	assert.Nil(t, err)
+	assert.Equal(t, 35, *after.Properties["Reference"].MaxLength)
+
+	components, err := GenerateOpenAPIComponents("country", schemaAsOf.Add(-time.Nanosecond), reference{})
+	assert.Nil(t, err)
+	assert.Equal(t, 18, *components.Schemas["reference_GB"].Properties["Reference"].MaxLength)
+}
@@ -0,0 +1,93 @@
+package tags
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// LengthUnit is what length rules count, chosen in the tag with a prefix such
+// as runes:1-35 and for the rules without one by WithLengthUnit.
+type LengthUnit string
+
+const (
+	// Bytes counts the bytes of the UTF-8 encoding, so Müller has length 7.
+	Bytes LengthUnit = "bytes"
+	// Runes counts Unicode code points, so Müller has length 6.
+	Runes LengthUnit = "runes"
+	// Graphemes counts user-perceived characters, so a letter followed by
+	// combining accents, a flag or an emoji joined with ZWJ counts once.
+	Graphemes LengthUnit = "graphemes"
+)
+
+// lengthUnits are the units a length literal can be prefixed with.
+var lengthUnits = []LengthUnit{Bytes, Runes, Graphemes}
+
+func isLengthUnit(name string) bool {
+	for _, unit := range lengthUnits {
+		if string(unit) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func lengthUnitNames() []string {
+	names := make([]string, 0, len(lengthUnits))
+	for _, unit := range lengthUnits {
+		names = append(names, string(unit))
+	}
+	return names
+}
+
+// Count returns the length of value in the unit, in bytes for an unknown unit.
+func (unit LengthUnit) Count(value string) int {
+	switch unit {
+	case Runes:
+		return utf8.RuneCountInString(value)
+	case Graphemes:
+		return countGraphemes(value)
+	}
+	return len(value)
+}
+
+const zeroWidthJoiner = '\u200d'
+
+// countGraphemes counts the extended grapheme clusters of value as UAX #29
+// splits them for the scripts of names and references: a cluster is a rune
+// followed by its combining marks, variation selectors, emoji modifiers and
+// Hangul vowels and finals, CR LF, a pair of regional indicators or symbols
+// joined with ZWJ. Indic conjuncts count once per consonant.
+func countGraphemes(value string) int {
+	count := 0
+	previous := rune(-1)
+	regionalIndicators := 0
+	for _, r := range value {
+		joined := previous >= 0 && (previous == '\r' && r == '\n' ||
+			isGraphemeExtend(r) ||
+			previous == zeroWidthJoiner && unicode.Is(unicode.So, r) ||
+			isRegionalIndicator(r) && regionalIndicators%2 == 1)
+		if !joined {
+			count++
+		}
+
+		if isRegionalIndicator(r) {
+			regionalIndicators++
+		} else if !isGraphemeExtend(r) {
+			regionalIndicators = 0
+		}
+		previous = r
+	}
+	return count
+}
+
+func isGraphemeExtend(r rune) bool {
+	return unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || unicode.Is(unicode.Mc, r) ||
+		r == zeroWidthJoiner ||
+		r >= 0x1F3FB && r <= 0x1F3FF || // emoji modifiers
+		r >= 0xE0020 && r <= 0xE007F || // tags of subdivision flags
+		r >= 0x1160 && r <= 0x11FF // Hangul vowel and final jamo
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
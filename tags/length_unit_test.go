@@ -0,0 +1,45 @@
+package tags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LengthUnitCount(t *testing.T) {
+	cases := []struct {
+		description       string
+		value             string
+		expectedBytes     int
+		expectedRunes     int
+		expectedGraphemes int
+	}{
+		{description: "empty", value: "", expectedBytes: 0, expectedRunes: 0, expectedGraphemes: 0},
+		{description: "ASCII", value: "Smith", expectedBytes: 5, expectedRunes: 5, expectedGraphemes: 5},
+		{description: "precomposed accent", value: "Müller", expectedBytes: 7, expectedRunes: 6, expectedGraphemes: 6},
+		{description: "combining accent", value: "Müller", expectedBytes: 8, expectedRunes: 7, expectedGraphemes: 6},
+		{description: "Devanagari vowel signs", value: "किताब", expectedBytes: 15, expectedRunes: 5, expectedGraphemes: 3},
+		{description: "Han", value: "王小明", expectedBytes: 9, expectedRunes: 3, expectedGraphemes: 3},
+		{description: "Hangul jamo", value: "각", expectedBytes: 9, expectedRunes: 3, expectedGraphemes: 1},
+		{description: "CR LF", value: "a\r\nb", expectedBytes: 4, expectedRunes: 4, expectedGraphemes: 3},
+		{description: "flags", value: "🇬🇧🇮🇪", expectedBytes: 16, expectedRunes: 4, expectedGraphemes: 2},
+		{description: "emoji modifier", value: "👍🏽", expectedBytes: 8, expectedRunes: 2, expectedGraphemes: 1},
+		{description: "ZWJ sequence", value: "👩‍💻", expectedBytes: 11, expectedRunes: 3, expectedGraphemes: 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			assert.Equal(t, c.expectedBytes, Bytes.Count(c.value))
+			assert.Equal(t, c.expectedRunes, Runes.Count(c.value))
+			assert.Equal(t, c.expectedGraphemes, Graphemes.Count(c.value))
+		})
+	}
+}
+
+func Test_CountGraphemesDoesNotAllocate(t *testing.T) {
+	allocations := testing.AllocsPerRun(100, func() {
+		Graphemes.Count("Müller 👩‍💻 🇬🇧")
+	})
+
+	assert.Equal(t, 0.0, allocations)
+}
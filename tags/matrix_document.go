@@ -0,0 +1,250 @@
+package tags
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DocumentFormat is the output format of WriteMatrixDocument and WriteOpenAPIComponents.
+type DocumentFormat string
+
+const (
+	MarkdownDocument DocumentFormat = "markdown"
+	CSVDocument      DocumentFormat = "csv"
+	HTMLDocument     DocumentFormat = "html"
+)
+
+// MatrixTable is the validation matrix of a type with its tagged fields in
+// declaration order, ready to be rendered as a fields by countries table.
+type MatrixTable struct {
+	TypeName string
+	Fields   []string
+	Matrix   ValidationMatrix
+	// Kinds are the kinds of the fields that are not strings.
+	Kinds map[string]FieldKind
+}
+
+// NewMatrixTable builds the table of a struct, or pointer to struct, with CreateValidationMatrix.
+func NewMatrixTable(i interface{}) (*MatrixTable, error) {
+	t := reflect.TypeOf(i)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	matrix, err := CreateValidationMatrix(reflect.Zero(t).Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	table := &MatrixTable{TypeName: t.Name(), Matrix: matrix, Kinds: make(map[string]FieldKind)}
+	for _, field := range structFields(t) {
+		if _, hasRules := matrix[field.Name]; hasRules {
+			table.Fields = append(table.Fields, field.Name)
+			if field.Kind != StringKind {
+				table.Kinds[field.Name] = field.Kind
+			}
+		}
+	}
+	return table, nil
+}
+
+// Countries returns the countries with rules for at least one field, sorted.
+func (table *MatrixTable) Countries() []string {
+	return matrixCountries([]*MatrixTable{table})
+}
+
+// kind returns the kind of a field, strings being left out of Kinds.
+func (table *MatrixTable) kind(field string) FieldKind {
+	if kind, ok := table.Kinds[field]; ok {
+		return kind
+	}
+	return StringKind
+}
+
+// Cell describes the rules of a field for a country, one period after the
+// other, or returns "" when there are none.
+func (table *MatrixTable) Cell(field, country string) string {
+	var periods []string
+	if validationInfos := table.Matrix[field]; validationInfos != nil {
+		for period := (*validationInfos)[country]; period != nil; period = period.next {
+			periods = append(periods, period.Describe())
+		}
+	}
+	return strings.Join(periods, "; ")
+}
+
+// Describe summarises the rules for readers of the tags, e.g. "required, 7 to 10 bytes".
+// Lengths without a unit are counted in bytes, as the default Validator counts them.
+func (countryValidationInfo *CountryValidationInfo) Describe() string {
+	var rules []string
+	if countryValidationInfo.required {
+		rules = append(rules, "required")
+	}
+	if countryValidationInfo.requiredIf != nil {
+		rules = append(rules, "required if "+countryValidationInfo.requiredIf.name+" is set")
+	}
+	if countryValidationInfo.requiredUnless != nil {
+		rules = append(rules, "required unless "+countryValidationInfo.requiredUnless.name+" is set")
+	}
+	unit := Bytes
+	if countryValidationInfo.lengthUnit != "" {
+		unit = countryValidationInfo.lengthUnit
+	}
+	if countryValidationInfo.minLen == countryValidationInfo.maxLen && countryValidationInfo.maxLen > 0 {
+		rules = append(rules, fmt.Sprintf("exactly %d %s", countryValidationInfo.maxLen, unit))
+	} else if countryValidationInfo.maxLen > 0 && countryValidationInfo.minLen == 0 {
+		rules = append(rules, fmt.Sprintf("up to %d %s", countryValidationInfo.maxLen, unit))
+	} else if countryValidationInfo.maxLen > 0 {
+		rules = append(rules, fmt.Sprintf("%d to %d %s", countryValidationInfo.minLen, countryValidationInfo.maxLen, unit))
+	}
+	if len(countryValidationInfo.oneOf) > 0 {
+		rules = append(rules, "one of "+strings.Join(countryValidationInfo.oneOf, ", "))
+	}
+	if countryValidationInfo.pattern != nil {
+		rules = append(rules, "matching "+countryValidationInfo.pattern.String())
+	}
+	if countryValidationInfo.charset != nil {
+		rules = append(rules, "in charset "+countryValidationInfo.charset.Name())
+	}
+	for _, reference := range countryValidationInfo.comparisons {
+		rules = append(rules, fieldComparisons[reference.rule].description+" "+reference.name)
+	}
+	if len(countryValidationInfo.normalizers) > 0 {
+		rules = append(rules, "normalized by "+strings.Join(countryValidationInfo.normalizers, ", "))
+	}
+	if len(rules) == 0 {
+		rules = append(rules, "no rules")
+	}
+	if !countryValidationInfo.since.IsZero() {
+		rules = append(rules, "from "+formatDate(countryValidationInfo.since))
+	}
+	if !countryValidationInfo.until.IsZero() {
+		rules = append(rules, "until "+formatDate(countryValidationInfo.until))
+	}
+	return strings.Join(rules, ", ")
+}
+
+// RenderValidationMatrix writes the validation matrix of each struct in values as a document.
+func RenderValidationMatrix(w io.Writer, format DocumentFormat, values ...interface{}) error {
+	var tables []*MatrixTable
+	for _, value := range values {
+		table, err := NewMatrixTable(value)
+		if err != nil {
+			return err
+		}
+		tables = append(tables, table)
+	}
+	return WriteMatrixDocument(w, format, tables...)
+}
+
+// WriteMatrixDocument renders tables with one row per field and one column per
+// country. Markdown and HTML get a section per table, CSV a single table with
+// the type as first column and the countries of all tables.
+func WriteMatrixDocument(w io.Writer, format DocumentFormat, tables ...*MatrixTable) error {
+	switch format {
+	case MarkdownDocument:
+		return writeMarkdownDocument(w, tables)
+	case CSVDocument:
+		return writeCSVDocument(w, tables)
+	case HTMLDocument:
+		return htmlDocument.Execute(w, tables)
+	}
+	return fmt.Errorf("unknown document format %s", format)
+}
+
+func writeMarkdownDocument(w io.Writer, tables []*MatrixTable) error {
+	escape := strings.NewReplacer("|", `\|`).Replace
+
+	var document strings.Builder
+	for i, table := range tables {
+		if i > 0 {
+			document.WriteString("\n")
+		}
+		countries := table.Countries()
+		fmt.Fprintf(&document, "## %s\n\n| Field |", escape(table.TypeName))
+		for _, country := range countries {
+			fmt.Fprintf(&document, " %s |", escape(country))
+		}
+		document.WriteString("\n| --- |" + strings.Repeat(" --- |", len(countries)) + "\n")
+
+		for _, field := range table.Fields {
+			fmt.Fprintf(&document, "| %s |", escape(field))
+			for _, country := range countries {
+				fmt.Fprintf(&document, " %s |", escape(table.Cell(field, country)))
+			}
+			document.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(w, document.String())
+	return err
+}
+
+func writeCSVDocument(w io.Writer, tables []*MatrixTable) error {
+	countries := matrixCountries(tables)
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(append([]string{"Type", "Field"}, countries...)); err != nil {
+		return err
+	}
+	for _, table := range tables {
+		for _, field := range table.Fields {
+			record := []string{table.TypeName, field}
+			for _, country := range countries {
+				record = append(record, table.Cell(field, country))
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+var htmlDocument = template.Must(template.New("matrix").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>f3_validate rules</title>
+</head>
+<body>
+{{- range $table := . }}
+<h2>{{ $table.TypeName }}</h2>
+<table>
+<thead>
+<tr><th>Field</th>{{ range $table.Countries }}<th>{{ . }}</th>{{ end }}</tr>
+</thead>
+<tbody>
+{{- range $field := $table.Fields }}
+<tr><th>{{ $field }}</th>{{ range $country := $table.Countries }}<td>{{ $table.Cell $field $country }}</td>{{ end }}</tr>
+{{- end }}
+</tbody>
+</table>
+{{- end }}
+</body>
+</html>
+`))
+
+func matrixCountries(tables []*MatrixTable) []string {
+	seen := make(map[string]bool)
+	var countries []string
+	for _, table := range tables {
+		for _, validationInfos := range table.Matrix {
+			for country := range *validationInfos {
+				if !seen[country] {
+					seen[country] = true
+					countries = append(countries, country)
+				}
+			}
+		}
+	}
+	sort.Strings(countries)
+	return countries
+}
@@ -0,0 +1,112 @@
+package tags
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type payment struct {
+	Reference string `f3_validate:"[GB:1-18 | PT:required, 0-140]"`
+	Amount    string
+}
+
+func Test_RenderValidationMatrixAsMarkdown(t *testing.T) {
+	var document bytes.Buffer
+
+	err := RenderValidationMatrix(&document, MarkdownDocument, account{}, &payment{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, `## account
+
+| Field | AU | GB | PT |
+| --- | --- | --- | --- |
+| BankId |  | required, 7 to 10 bytes | exactly 5 bytes |
+| IBAN | required, exactly 4 bytes | exactly 8 bytes | required, 7 to 9 bytes |
+
+## payment
+
+| Field | GB | PT |
+| --- | --- | --- |
+| Reference | 1 to 18 bytes | required, up to 140 bytes |
+`, document.String())
+}
+
+func Test_RenderValidationMatrixAsCSV(t *testing.T) {
+	var document bytes.Buffer
+
+	err := RenderValidationMatrix(&document, CSVDocument, account{}, payment{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, `Type,Field,AU,GB,PT
+account,BankId,,"required, 7 to 10 bytes",exactly 5 bytes
+account,IBAN,"required, exactly 4 bytes",exactly 8 bytes,"required, 7 to 9 bytes"
+payment,Reference,,1 to 18 bytes,"required, up to 140 bytes"
+`, document.String())
+}
+
+func Test_RenderValidationMatrixAsHTML(t *testing.T) {
+	var document bytes.Buffer
+
+	err := RenderValidationMatrix(&document, HTMLDocument, payment{})
+
+	assert.Nil(t, err)
+	assert.Contains(t, document.String(), "<h2>payment</h2>")
+	assert.Contains(t, document.String(), "<tr><th>Field</th><th>GB</th><th>PT</th></tr>")
+	assert.Contains(t, document.String(), "<tr><th>Reference</th><td>1 to 18 bytes</td><td>required, up to 140 bytes</td></tr>")
+}
+
+func Test_WriteMatrixDocumentEscapesCells(t *testing.T) {
+	table := &MatrixTable{TypeName: "a|b<c>", Fields: []string{"F"}, Matrix: ValidationMatrix{"F": &CountriesValidationInfos{"GB": {required: true}}}}
+
+	var markdown, html bytes.Buffer
+	assert.Nil(t, WriteMatrixDocument(&markdown, MarkdownDocument, table))
+	assert.Nil(t, WriteMatrixDocument(&html, HTMLDocument, table))
+
+	assert.Contains(t, markdown.String(), `## a\|b<c>`)
+	assert.Contains(t, html.String(), "<h2>a|b&lt;c&gt;</h2>")
+}
+
+func Test_WriteMatrixDocumentRejectsUnknownFormat(t *testing.T) {
+	err := WriteMatrixDocument(&bytes.Buffer{}, DocumentFormat("pdf"))
+
+	assert.EqualError(t, err, "unknown document format pdf")
+}
+
+func Test_RenderValidationMatrixReturnsTagErrors(t *testing.T) {
+	err := RenderValidationMatrix(&bytes.Buffer{}, CSVDocument, wrongAccountStruct{})
+
+	assert.NotNil(t, err)
+}
+
+func Test_DescribePatternAndOneOf(t *testing.T) {
+	table, err := NewMatrixTable(transfer{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "required, one of GBP, EUR", table.Cell("Currency", "GB"))
+	assert.Equal(t, "1 to 18 bytes, matching ^[A-Z0-9 ]+$", table.Cell("Reference", "GB"))
+}
+
+func Test_DescribeLengthUnit(t *testing.T) {
+	validationInfos, err := CompileCountriesValidationInfos("[GB:runes:1-35 | IE:graphemes:10]")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "1 to 35 runes", validationInfos["GB"].Describe())
+	assert.Equal(t, "exactly 10 graphemes", validationInfos["IE"].Describe())
+}
+
+func Test_DescribeCharset(t *testing.T) {
+	validationInfos, err := CompileCountriesValidationInfos("[GB:1-35,charset(fps)]")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "1 to 35 bytes, in charset fps", validationInfos["GB"].Describe())
+}
+
+func Test_CellDescribesEachPeriod(t *testing.T) {
+	validationInfos, err := CompileCountriesValidationInfos("[GB:1-35,required,since(2026-03-01) | GB:1-18,until(2026-03-01)]")
+	assert.Nil(t, err)
+	table := &MatrixTable{Fields: []string{"Reference"}, Matrix: ValidationMatrix{"Reference": &validationInfos}}
+
+	assert.Equal(t, "1 to 18 bytes, until 2026-03-01; required, 1 to 35 bytes, from 2026-03-01", table.Cell("Reference", "GB"))
+}
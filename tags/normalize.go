@@ -0,0 +1,113 @@
+package tags
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// normalizers are the rules that rewrite a value before the other rules of
+// its clause check it, in the order the tag gives them. They return the value
+// itself, without allocating, when they leave it as it is.
+var normalizers = map[string]func(string) string{
+	"trim":  strings.TrimSpace,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"nospace": func(value string) string {
+		return strings.Map(func(r rune) rune {
+			if unicode.IsSpace(r) {
+				return -1
+			}
+			return r
+		}, value)
+	},
+	"digitsonly": func(value string) string {
+		return strings.Map(func(r rune) rune {
+			if r < '0' || r > '9' {
+				return -1
+			}
+			return r
+		}, value)
+	},
+}
+
+func compileNormalizer(countryValidationInfo *CountryValidationInfo, rule *RuleNode) error {
+	if err := expectArgs(rule, 0, 0); err != nil {
+		return err
+	}
+	countryValidationInfo.normalizers = append(countryValidationInfo.normalizers, rule.Name)
+	if countryValidationInfo.normalizedAt == nil {
+		countryValidationInfo.normalizedAt = &ruleLocation{rule: rule.Name, position: rule.Span.Start}
+	}
+	return nil
+}
+
+// ApplyNormalizers returns value rewritten by the named normalizers in turn,
+// as the ValidateF3 methods of WriteValidators do before checking it.
+func ApplyNormalizers(value string, names ...string) string {
+	for _, name := range names {
+		if normalizer := normalizers[name]; normalizer != nil {
+			value = normalizer(value)
+		}
+	}
+	return value
+}
+
+// NormalizedField is a value Normalize rewrote.
+type NormalizedField struct {
+	Field  string
+	Before string
+	After  string
+}
+
+// Normalize rewrites the fields of the struct ptr points to with the
+// normalizers of their tags for a country, e.g. trim or upper, and returns
+// the fields it changed.
+func Normalize(ptr interface{}, country string) ([]NormalizedField, error) {
+	return defaultValidator.Normalize(context.Background(), ptr, Dimensions{CountryDimension: country})
+}
+
+// Normalize rewrites the fields of the struct ptr points to with the
+// normalizers of the rules Validate would apply for the dimensions, and
+// returns the fields it changed in field order. Nothing is written when a
+// field to change cannot be set.
+func (validator *Validator) Normalize(ctx context.Context, ptr interface{}, dimensions Dimensions) ([]NormalizedField, error) {
+	value := reflect.ValueOf(ptr)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Normalize needs a pointer to a struct but found %T", ptr)
+	}
+	asOf, ok := AsOfFromContext(ctx)
+	if !ok {
+		asOf = validator.clock()
+	}
+
+	compiled, err := validator.compiledType(ptr)
+	if err != nil {
+		return nil, err
+	}
+
+	value = value.Elem()
+	var normalized []NormalizedField
+	for f := range compiled.plan.fields {
+		field := &compiled.plan.fields[f]
+		countryValidationInfo := field.rulesFor(dimensions, asOf)
+		if countryValidationInfo == nil || len(countryValidationInfo.normalizers) == 0 {
+			continue
+		}
+
+		before := value.Field(field.index).String()
+		if after := ApplyNormalizers(before, countryValidationInfo.normalizers...); after != before {
+			if !value.Field(field.index).CanSet() {
+				return nil, fmt.Errorf("cannot normalize unexported field %s.%s", value.Type(), field.name)
+			}
+			normalized = append(normalized, NormalizedField{Field: field.name, Before: before, After: after})
+		}
+	}
+
+	for _, change := range normalized {
+		value.FieldByName(change.Field).SetString(change.After)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,113 @@
+package tags
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ApplyNormalizers(t *testing.T) {
+	cases := []struct {
+		description string
+		value       string
+		normalizers []string
+		expected    string
+	}{
+		{description: "no normalizers", value: " gb29 nwbk ", normalizers: nil, expected: " gb29 nwbk "},
+		{description: "trim", value: " \tNWBKGB2L\n", normalizers: []string{"trim"}, expected: "NWBKGB2L"},
+		{description: "upper", value: "nwbkgb2l", normalizers: []string{"upper"}, expected: "NWBKGB2L"},
+		{description: "lower", value: "Jürgen", normalizers: []string{"lower"}, expected: "jürgen"},
+		{description: "nospace", value: "GB29 NWBK 6016\t1331", normalizers: []string{"nospace"}, expected: "GB29NWBK60161331"},
+		{description: "digitsonly", value: "60-16-13", normalizers: []string{"digitsonly"}, expected: "601613"},
+		{description: "in order", value: " gb29 nwbk ", normalizers: []string{"nospace", "upper"}, expected: "GB29NWBK"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			assert.Equal(t, c.expected, ApplyNormalizers(c.value, c.normalizers...))
+		})
+	}
+}
+
+func Test_ApplyNormalizersDoesNotAllocateForNormalValues(t *testing.T) {
+	allocations := testing.AllocsPerRun(100, func() {
+		ApplyNormalizers("GB29NWBK60161331926819", "trim", "upper", "nospace")
+	})
+
+	assert.Equal(t, 0.0, allocations)
+}
+
+type bankDetails struct {
+	IBAN     string `f3_validate:"[GB,IE:nospace,upper,15-34,pattern('^[A-Z]{2}[0-9]{2}[A-Z0-9]+$')]"`
+	BIC      string `f3_validate:"[GB,IE:trim,upper,8-11]"`
+	SortCode string `f3_validate:"[GB:digitsonly,pattern('^[0-9]{6}$')]"`
+}
+
+func Test_ValidateChecksNormalizedValues(t *testing.T) {
+	details := bankDetails{IBAN: "gb29 nwbk 6016 1331 9268 19", BIC: " nwbkgb2l ", SortCode: "60-16-13"}
+
+	validationErrors, err := Validate(details, "GB")
+	assert.Nil(t, err)
+	assert.Nil(t, validationErrors)
+
+	validationErrors, err = Validate(bankDetails{BIC: " nwbk ", SortCode: "60-16"}, "GB")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"field BIC must have size from 8 to 11 bytes when country is GB but found size 4",
+		"field SortCode must match ^[0-9]{6}$ when country is GB but found 6016",
+	}, validationErrors)
+}
+
+func Test_NormalizeWritesNormalizedValuesBack(t *testing.T) {
+	details := &bankDetails{IBAN: "ie29 aibk 9311 5212 3456 78", BIC: "AIBKIE2D", SortCode: "93-11-52"}
+
+	normalized, err := Normalize(details, "IE")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []NormalizedField{{Field: "IBAN", Before: "ie29 aibk 9311 5212 3456 78", After: "IE29AIBK93115212345678"}}, normalized)
+	assert.Equal(t, &bankDetails{IBAN: "IE29AIBK93115212345678", BIC: "AIBKIE2D", SortCode: "93-11-52"}, details)
+}
+
+func Test_NormalizeUsesRulesOfDimensions(t *testing.T) {
+	details := &bankDetails{BIC: " nwbkgb2l", SortCode: "60-16-13"}
+
+	normalized, err := mustValidator(t).Normalize(context.Background(), details, Dimensions{CountryDimension: "GB"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []NormalizedField{
+		{Field: "BIC", Before: " nwbkgb2l", After: "NWBKGB2L"},
+		{Field: "SortCode", Before: "60-16-13", After: "601613"},
+	}, normalized)
+	assert.Equal(t, &bankDetails{BIC: "NWBKGB2L", SortCode: "601613"}, details)
+}
+
+func Test_NormalizeRejectsWhatItCannotWrite(t *testing.T) {
+	type unexported struct {
+		Reference string `f3_validate:"[GB:trim]"`
+		bic       string `f3_validate:"[GB:upper]"`
+	}
+	value := &unexported{Reference: " INV ", bic: "nwbkgb2l"}
+
+	_, err := Normalize(bankDetails{}, "GB")
+	assert.EqualError(t, err, "Normalize needs a pointer to a struct but found tags.bankDetails")
+
+	_, err = Normalize(value, "GB")
+	assert.EqualError(t, err, "cannot normalize unexported field tags.unexported.bic")
+	assert.Equal(t, " INV ", value.Reference)
+}
+
+func Test_NormalizersRejectFieldsOtherThanStrings(t *testing.T) {
+	type settlementDays struct {
+		Days int `f3_validate:"[GB:1-2 | IE:trim,upper]"`
+	}
+
+	_, err := CreateValidationMatrix(settlementDays{})
+	assert.NotNil(t, err)
+	assert.Equal(t, CodeUnnormalizableField, err.(*TagError).Code)
+	assert.Equal(t, "field tags.settlementDays.Days (int): rule trim cannot normalize a field of type int in position 13, expected string\n\t[GB:1-2 | IE:trim,upper]\n\t             ^", err.Error())
+
+	_, err = Normalize(&settlementDays{Days: 1}, "IE")
+	assert.NotNil(t, err)
+	assert.Equal(t, CodeUnnormalizableField, err.(*TagError).Code)
+}
@@ -0,0 +1,124 @@
+package tags
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	JSONDocument DocumentFormat = "json"
+	YAMLDocument DocumentFormat = "yaml"
+)
+
+// OpenAPIComponentsRef is the prefix of references to generated component schemas.
+const OpenAPIComponentsRef = "#/components/schemas/"
+
+// OpenAPIDiscriminator selects the variant of a oneOf schema by the value of a property.
+type OpenAPIDiscriminator struct {
+	PropertyName string            `json:"propertyName" yaml:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty" yaml:"mapping,omitempty"`
+}
+
+// OpenAPIComponents is the components object of an OpenAPI 3.1 document, of
+// which only the schemas are generated.
+type OpenAPIComponents struct {
+	Schemas map[string]*JSONSchema `json:"schemas" yaml:"schemas"`
+}
+
+// GenerateOpenAPIComponents returns the component schemas of the structs in
+// values. Each struct T gets a T_CC variant per country CC with rules, holding
+// the schema of GenerateJSONSchema and countryProperty set to the country, and a
+// T schema choosing between them with a discriminator on countryProperty.
+// Both carry the compiled rules of each property in x-f3-country-rules, the
+// variants only those in effect at asOf, so that the components only depend on
+// the arguments. Rules of payment schemes and other dimensions are left out of
+// the variants.
+func GenerateOpenAPIComponents(countryProperty string, asOf time.Time, values ...interface{}) (*OpenAPIComponents, error) {
+	components := &OpenAPIComponents{Schemas: make(map[string]*JSONSchema)}
+
+	for _, value := range values {
+		t, fields, matrix, err := jsonSchemaFields(value)
+		if err != nil {
+			return nil, err
+		}
+		name := t.Name()
+		if name == "" {
+			return nil, fmt.Errorf("schema of %s has no name", t)
+		}
+		if _, generated := components.Schemas[name]; generated {
+			return nil, fmt.Errorf("schema %s generated twice", name)
+		}
+
+		countries := schemelessCountries(matrix)
+		if len(countries) == 0 {
+			schema := countryJSONSchema(fields, matrix, "", asOf)
+			schema.Title = name
+			components.Schemas[name] = schema
+			continue
+		}
+
+		schema := &JSONSchema{
+			Title:         name,
+			Type:          "object",
+			Discriminator: &OpenAPIDiscriminator{PropertyName: countryProperty, Mapping: make(map[string]string)},
+			CountryRules:  make(map[string]string),
+		}
+		for _, field := range fields {
+			schema.CountryRules[field.propertyName] = matrix[field.fieldName].String()
+		}
+
+		for _, country := range countries {
+			country := country
+			variantName := name + "_" + country
+			variant := countryJSONSchema(fields, matrix, country, asOf)
+			variant.Title = variantName
+			variant.Properties[countryProperty] = &JSONSchema{Type: "string", Const: &country}
+			variant.Required = append([]string{countryProperty}, variant.Required...)
+			variant.CountryRules = make(map[string]string)
+			for _, field := range fields {
+				if countryValidationInfo := (*matrix[field.fieldName])[country].at(asOf); countryValidationInfo != nil {
+					variant.CountryRules[field.propertyName] = countryValidationInfo.String()
+				}
+			}
+			if _, generated := components.Schemas[variantName]; generated {
+				return nil, fmt.Errorf("schema %s generated twice", variantName)
+			}
+			components.Schemas[variantName] = variant
+
+			schema.OneOf = append(schema.OneOf, &JSONSchema{Ref: OpenAPIComponentsRef + variantName})
+			schema.Discriminator.Mapping[country] = OpenAPIComponentsRef + variantName
+		}
+		components.Schemas[name] = schema
+	}
+
+	return components, nil
+}
+
+// WriteOpenAPIComponents writes components under a components key as JSON or
+// YAML. Keys of maps are sorted and keywords keep a fixed order, so the same
+// types always give the same document.
+func WriteOpenAPIComponents(w io.Writer, format DocumentFormat, components *OpenAPIComponents) error {
+	document := struct {
+		Components *OpenAPIComponents `json:"components" yaml:"components"`
+	}{components}
+
+	switch format {
+	case JSONDocument:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(document)
+	case YAMLDocument:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(document); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+	return fmt.Errorf("unknown document format %s", format)
+}
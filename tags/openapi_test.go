@@ -0,0 +1,70 @@
+package tags
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type note struct {
+	Text string
+}
+
+func Test_WriteOpenAPIComponentsMatchesGoldenDocuments(t *testing.T) {
+	components, err := GenerateOpenAPIComponents("country", schemaAsOf, transfer{}, &account{}, note{})
+	assert.Nil(t, err)
+
+	for _, format := range []DocumentFormat{JSONDocument, YAMLDocument} {
+		t.Run(string(format), func(t *testing.T) {
+			var document bytes.Buffer
+			assert.Nil(t, WriteOpenAPIComponents(&document, format, components))
+
+			golden := filepath.Join("testdata", "openapi", "components."+string(format))
+			if *update {
+				assert.Nil(t, os.WriteFile(golden, document.Bytes(), 0o644))
+			}
+			expected, err := os.ReadFile(golden)
+			if !assert.Nil(t, err, "missing golden document, run go test -run OpenAPI -update") {
+				return
+			}
+			assert.Equal(t, string(expected), document.String())
+		})
+	}
+}
+
+func Test_GenerateOpenAPIComponentsDiscriminatesCountries(t *testing.T) {
+	components, err := GenerateOpenAPIComponents("country", schemaAsOf, account{})
+	assert.Nil(t, err)
+
+	schema := components.Schemas["account"]
+	assert.Equal(t, "country", schema.Discriminator.PropertyName)
+	assert.Equal(t, "#/components/schemas/account_GB", schema.Discriminator.Mapping["GB"])
+	assert.Equal(t, []*JSONSchema{{Ref: "#/components/schemas/account_AU"}, {Ref: "#/components/schemas/account_GB"}, {Ref: "#/components/schemas/account_PT"}}, schema.OneOf)
+	assert.Equal(t, "[GB:7-10,required | PT:5]", schema.CountryRules["BankId"])
+
+	variant := components.Schemas["account_GB"]
+	assert.Equal(t, "GB", *variant.Properties["country"].Const)
+	assert.Equal(t, []string{"country", "BankId"}, variant.Required)
+	assert.Equal(t, map[string]string{"BankId": "7-10,required", "IBAN": "8"}, variant.CountryRules)
+}
+
+func Test_GenerateOpenAPIComponentsRejectsDuplicateSchemas(t *testing.T) {
+	_, err := GenerateOpenAPIComponents("country", schemaAsOf, account{}, &account{})
+
+	assert.EqualError(t, err, "schema account generated twice")
+}
+
+func Test_GenerateOpenAPIComponentsRejectsAnonymousStructs(t *testing.T) {
+	_, err := GenerateOpenAPIComponents("country", schemaAsOf, struct{ Name string }{})
+
+	assert.EqualError(t, err, "schema of struct { Name string } has no name")
+}
+
+func Test_WriteOpenAPIComponentsRejectsUnknownFormat(t *testing.T) {
+	err := WriteOpenAPIComponents(&bytes.Buffer{}, CSVDocument, &OpenAPIComponents{})
+
+	assert.EqualError(t, err, "unknown document format csv")
+}
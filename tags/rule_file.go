@@ -0,0 +1,189 @@
+package tags
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RuleSet holds validation rules loaded from rule files, keyed by the type they
+// apply to as reflect names it, e.g. payments.Account.
+type RuleSet map[string]ValidationMatrix
+
+// ruleFile is the layout of a rule file: types, then fields, then countries,
+// each country holding the rules of a tag clause, or a list of them for
+// different periods, e.g.
+//
+//	types:
+//	  payments.Account:
+//	    BankId:
+//	      GB: 7-10,required
+//	      IE,PT: "5"
+//	    Reference:
+//	      GB:
+//	        - rules: 1-18
+//	          until: 2026-03-01
+//	        - 1-35,since(2026-03-01)
+type ruleFile struct {
+	Types map[string]map[string]map[string]ruleFileEntries `json:"types" yaml:"types"`
+}
+
+// ruleFileEntry is the rules of a country, with from and until as another way
+// of writing their since and until rules.
+type ruleFileEntry struct {
+	Rules string `json:"rules" yaml:"rules"`
+	From  string `json:"from" yaml:"from"`
+	Until string `json:"until" yaml:"until"`
+}
+
+// ruleFileEntries are the entries of a country, written as a single entry or
+// a list of them, an entry being its rules or an object.
+type ruleFileEntries []ruleFileEntry
+
+func (entries *ruleFileEntries) UnmarshalJSON(data []byte) error {
+	var list []json.RawMessage
+	if err := json.Unmarshal(data, &list); err != nil {
+		list = []json.RawMessage{data}
+	}
+
+	*entries = nil
+	for _, item := range list {
+		var entry ruleFileEntry
+		if err := json.Unmarshal(item, &entry.Rules); err != nil {
+			if err := json.Unmarshal(item, &entry); err != nil {
+				return err
+			}
+		}
+		*entries = append(*entries, entry)
+	}
+	return nil
+}
+
+func (entries *ruleFileEntries) UnmarshalYAML(value *yaml.Node) error {
+	list := []*yaml.Node{value}
+	if value.Kind == yaml.SequenceNode {
+		list = value.Content
+	}
+
+	*entries = nil
+	for _, item := range list {
+		var entry ruleFileEntry
+		var err error
+		if item.Kind == yaml.ScalarNode {
+			err = item.Decode(&entry.Rules)
+		} else {
+			err = item.Decode(&entry)
+		}
+		if err != nil {
+			return err
+		}
+		*entries = append(*entries, entry)
+	}
+	return nil
+}
+
+// clauseRules returns the rules of the entry as those of a tag clause.
+func (entry ruleFileEntry) clauseRules() string {
+	var rules []string
+	if entry.Rules != "" {
+		rules = append(rules, entry.Rules)
+	}
+	if entry.From != "" {
+		rules = append(rules, "since"+string(argumentsOpener)+formatArg(entry.From)+string(argumentsCloser))
+	}
+	if entry.Until != "" {
+		rules = append(rules, "until"+string(argumentsOpener)+formatArg(entry.Until)+string(argumentsCloser))
+	}
+	return strings.Join(rules, string(validationSeparator))
+}
+
+// LoadRuleFile reads a rule file, as JSON when its extension is .json and as
+// YAML otherwise.
+func LoadRuleFile(path string) (RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	format := YAMLDocument
+	if filepath.Ext(path) == ".json" {
+		format = JSONDocument
+	}
+	rules, err := ParseRuleFile(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// ParseRuleFile compiles the rules of a JSON or YAML rule file. The rules of a
+// country are compiled as the clause of a tag, "[COUNTRY:RULES]", so malformed
+// ones are reported as a *TagError with their type and field.
+func ParseRuleFile(data []byte, format DocumentFormat) (RuleSet, error) {
+	var file ruleFile
+	var err error
+	switch format {
+	case JSONDocument:
+		err = json.Unmarshal(data, &file)
+	case YAMLDocument:
+		err = yaml.Unmarshal(data, &file)
+	default:
+		return nil, fmt.Errorf("unknown document format %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make(RuleSet)
+	for typeName, fields := range file.Types {
+		matrix := make(ValidationMatrix)
+		for fieldName, countries := range fields {
+			validationInfos, err := compileRuleFileField(countries)
+			if err != nil {
+				if tagError, ok := err.(*TagError); ok {
+					tagError.StructType = typeName
+					tagError.FieldName = fieldName
+				}
+				return nil, err
+			}
+			matrix[fieldName] = &validationInfos
+		}
+		rules[typeName] = matrix
+	}
+	return rules, nil
+}
+
+// compileRuleFileField compiles the entries of a field as the clauses of a single
+// tag, so countries are checked as in tags. Each entry is first parsed on its
+// own, to tell rules that would spill into another clause.
+func compileRuleFileField(countries map[string]ruleFileEntries) (CountriesValidationInfos, error) {
+	keys := make([]string, 0, len(countries))
+	for key := range countries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var clauses []string
+	for _, key := range keys {
+		for _, entry := range countries[key] {
+			clause := key + string(countryValidationInitializer) + entry.clauseRules()
+			source := string(validationOpener) + clause + string(validationCloser)
+			tag, err := ParseTag(source)
+			if err != nil {
+				return nil, err
+			}
+			if len(tag.Clauses) > 1 {
+				position := tag.Clauses[0].Span.End + strings.IndexByte(source[tag.Clauses[0].Span.End:], byte(countrySeparator))
+				return nil, &TagError{Code: CodeUnexpectedSymbol, Tag: source, Reason: fmt.Sprintf("unexpected %c symbol", countrySeparator), Position: position}
+			}
+			clauses = append(clauses, clause)
+		}
+	}
+
+	return CompileCountriesValidationInfos(string(validationOpener) + strings.Join(clauses, " "+string(countrySeparator)+" ") + string(validationCloser))
+}
@@ -0,0 +1,102 @@
+package tags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LoadRuleFileReadsYAMLAndJSON(t *testing.T) {
+	for _, path := range []string{"testdata/rules/accounts.yaml", "testdata/rules/accounts.json"} {
+		t.Run(path, func(t *testing.T) {
+			rules, err := LoadRuleFile(path)
+
+			assert.Nil(t, err)
+			assert.Equal(t, "[GB:6-8 | IE:8,required | NL:8,required]", rules["tags.account"]["BankId"].String())
+			assert.Equal(t, []string{"GB"}, (*rules["tags.account"]["Country"])["GB"].oneOf)
+		})
+	}
+}
+
+func Test_ParseRuleFileAcceptsUnquotedYAMLLengths(t *testing.T) {
+	rules, err := ParseRuleFile([]byte("types:\n  tags.account:\n    BankId:\n      PT: 5\n"), YAMLDocument)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 5, (*rules["tags.account"]["BankId"])["PT"].maxLen)
+}
+
+func Test_ParseRuleFileReportsInvalidRules(t *testing.T) {
+	cases := []struct {
+		description          string
+		file                 string
+		expectedErrorMessage string
+	}{
+		{
+			description:          "unknown rule",
+			file:                 `{"types": {"tags.account": {"BankId": {"GB": "mandatory"}}}}`,
+			expectedErrorMessage: "unexpected token mandatory in position 4, expected charset, digitsonly, eqfield, gtfield, lower, ltfield, nefield, nospace, oneof, pattern, required, required_if, required_unless, since, trim, until or upper",
+		},
+		{
+			description:          "rules of another country",
+			file:                 `{"types": {"tags.account": {"BankId": {"GB": "7 | PT:5"}}}}`,
+			expectedErrorMessage: "unexpected | symbol in position 6",
+		},
+		{
+			description:          "country given twice",
+			file:                 `{"types": {"tags.account": {"BankId": {"GB": "7", "PT,GB": "5"}}}}`,
+			expectedErrorMessage: "country GB defined twice in position 11",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			_, err := ParseRuleFile([]byte(c.file), JSONDocument)
+
+			tagError, ok := err.(*TagError)
+			assert.True(t, ok)
+			assert.Equal(t, "tags.account", tagError.StructType)
+			assert.Equal(t, "BankId", tagError.FieldName)
+			assert.Equal(t, c.expectedErrorMessage, tagError.Summary())
+		})
+	}
+}
+
+func Test_LoadRuleFileReportsPathOfMalformedFiles(t *testing.T) {
+	_, err := LoadRuleFile("testdata/rules/missing.yaml")
+	assert.NotNil(t, err)
+
+	_, err = ParseRuleFile([]byte("types: ["), YAMLDocument)
+	assert.NotNil(t, err)
+
+	_, err = ParseRuleFile(nil, CSVDocument)
+	assert.EqualError(t, err, "unknown document format csv")
+}
+
+func Test_ParseRuleFileReadsPeriods(t *testing.T) {
+	jsonRules, err := ParseRuleFile([]byte(`{"types": {"tags.account": {"BankId": {
+		"GB": [{"rules": "6-8", "until": "2026-03-01"}, "6-10,since(2026-03-01)"],
+		"PT": {"rules": "4-5,required", "from": "2026-03-01T12:00:00+01:00"}
+	}}}}`), JSONDocument)
+	assert.Nil(t, err)
+
+	yamlRules, err := LoadRuleFile("testdata/rules/periods.yaml")
+	assert.Nil(t, err)
+
+	for _, rules := range []RuleSet{jsonRules, yamlRules} {
+		assert.Equal(t, "[GB:6-8,until(2026-03-01) | GB:6-10,since(2026-03-01) | PT:4-5,required,since('2026-03-01T12:00:00+01:00')]", rules["tags.account"]["BankId"].String())
+	}
+}
+
+func Test_ParseRuleFileRejectsOverlappingPeriods(t *testing.T) {
+	_, err := ParseRuleFile([]byte("types:\n  tags.account:\n    BankId:\n      GB: [6-8, 6-10]\n"), YAMLDocument)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, CodeDuplicateCountry, err.(*TagError).Code)
+}
+
+func Test_ParseRuleFileReadsSchemes(t *testing.T) {
+	rules, err := ParseRuleFile([]byte("types:\n  tags.payee:\n    AccountNumber:\n      GB/FPS: 8,required\n      GB: 6-8\n"), YAMLDocument)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "[GB:6-8 | GB/FPS:8,required]", rules["tags.payee"]["AccountNumber"].String())
+}
@@ -1,24 +1,32 @@
 package tags
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
 )
 
 const ValidationForm3TagName string = "f3_validate"
 
-type ValidationMatrix map[string]*map[string]*CountryValidationInfo
+type ValidationMatrix map[string]*CountriesValidationInfos
 
 func CreateValidationMatrix(i interface{}) (ValidationMatrix, error) {
 	t := reflect.TypeOf(i)
 
-	matrix := make(map[string]*map[string]*CountryValidationInfo)
+	matrix := make(ValidationMatrix)
 
 	for i := 0; i < t.NumField(); i++ {
 		validationTag := t.Field(i).Tag.Get(ValidationForm3TagName)
 		if len(validationTag) > 0 {
 			validationInfos, err := CompileCountriesValidationInfos(validationTag)
 			if err != nil {
+				if tagError, ok := err.(*TagError); ok {
+					tagError.StructType = t.String()
+					tagError.FieldName = t.Field(i).Name
+					tagError.FieldType = t.Field(i).Type.String()
+				}
 				return nil, err
 			}
 			fieldName := t.Field(i).Name
@@ -27,45 +35,122 @@ func CreateValidationMatrix(i interface{}) (ValidationMatrix, error) {
 		}
 	}
 
+	if err := CheckStructFields(t.String(), structFields(t), matrix); err != nil {
+		return nil, err
+	}
 	return matrix, nil
 }
 
+// defaultValidator validates for the functions of the package, caching the plan of each type.
+var defaultValidator = newValidator()
+
+// Validate returns the validation errors of a struct for a country, using the rules of its tags in effect now.
 func Validate(i interface{}, country string) ([]string, error) {
-	validationMatrix, err := CreateValidationMatrix(i)
-	var validationErrors []string = nil
+	return defaultValidator.Validate(context.Background(), i, Dimensions{CountryDimension: country})
+}
 
-	if err != nil {
-		return nil, err
+// ValidateScheme returns the validation errors of a struct for a country and payment scheme,
+// using the rules of its tags in effect now. Fields without rules for the scheme use those of the country.
+func ValidateScheme(i interface{}, country, scheme string) ([]string, error) {
+	return defaultValidator.Validate(context.Background(), i, Dimensions{CountryDimension: country, SchemeDimension: scheme})
+}
+
+// ValidateAt returns the validation errors of a struct for a country, using the rules of its tags in effect at asOf.
+func ValidateAt(i interface{}, country string, asOf time.Time) ([]string, error) {
+	return defaultValidator.Validate(ContextWithAsOf(context.Background(), asOf), i, Dimensions{CountryDimension: country})
+}
+
+// ValidationErrors are the validation errors of a struct, as returned by the
+// ValidateF3 methods of WriteValidators.
+type ValidationErrors []string
+
+func (validationErrors ValidationErrors) Error() string {
+	return strings.Join(validationErrors, "; ")
+}
+
+// Messages of the validation errors, shared with the code of WriteValidators.
+const (
+	requiredErrorFormat       = "field %s is required when %s"
+	lengthErrorFormat         = "field %s must have size from %d to %d %s when %s but found size %d"
+	patternErrorFormat        = "field %s must match %s when %s but found %s"
+	oneOfErrorFormat          = "field %s must be one of %s when %s but found %s"
+	requiredIfErrorFormat     = "field %s is required when %s and %s is set"
+	requiredUnlessErrorFormat = "field %s is required when %s and %s is not set"
+	charsetErrorFormat        = "field %s must only use characters of charset %s when %s but found %q in position %d"
+	comparisonErrorFormat     = "field %s must %s field %s when %s but found %s and %s"
+)
+
+// getValidationErrors checks a field of value, once normalized, against its
+// rules, lengths being counted in lengthUnit unless the rules give their own
+// unit. Fields other than strings are only compared. The field references of
+// the rules must be resolved.
+func getValidationErrors(dimensions Dimensions, value reflect.Value, fieldName string, field reflect.Value, validationInfo *CountryValidationInfo, lengthUnit LengthUnit) []string {
+	if field.Kind() != reflect.String {
+		return getComparisonErrors(dimensions, value, fieldName, field, validationInfo)
 	}
 
-	for fieldName, validationCountryMap := range validationMatrix {
-		fieldValue := getFieldValueByFieldName(i, fieldName)
-		if countryValidationInfo := (*validationCountryMap)[country]; countryValidationInfo != nil {
-			if errs := getValidationErrors(country, fieldName, fieldValue, countryValidationInfo); errs != nil {
-				validationErrors = append(validationErrors, errs...)
-			}
+	var validationErrors []string = nil
+	fieldValue := ApplyNormalizers(field.String(), validationInfo.normalizers...)
+
+	// Empty values are only checked by required, required_if and required_unless.
+	if fieldValue == "" {
+		if validationInfo.required {
+			validationErrors = append(validationErrors, fmt.Sprintf(requiredErrorFormat, fieldName, dimensions.describe()))
+			return validationErrors
 		}
+		if reference := validationInfo.requiredIf; reference != nil && !value.Field(reference.index).IsZero() {
+			validationErrors = append(validationErrors, fmt.Sprintf(requiredIfErrorFormat, fieldName, dimensions.describe(), reference.name))
+		}
+		if reference := validationInfo.requiredUnless; reference != nil && value.Field(reference.index).IsZero() {
+			validationErrors = append(validationErrors, fmt.Sprintf(requiredUnlessErrorFormat, fieldName, dimensions.describe(), reference.name))
+		}
+		return validationErrors
 	}
 
-	return validationErrors, nil
-}
-
-func getFieldValueByFieldName(i interface{}, fieldName string) string {
-	r := reflect.ValueOf(i)
-	f := reflect.Indirect(r).FieldByName(fieldName)
-	return f.String()
+	if validationInfo.maxLen > 0 {
+		if validationInfo.lengthUnit != "" {
+			lengthUnit = validationInfo.lengthUnit
+		}
+		actualLen := lengthUnit.Count(fieldValue)
+		if actualLen < validationInfo.minLen || actualLen > validationInfo.maxLen {
+			validationErrors = append(validationErrors, fmt.Sprintf(lengthErrorFormat, fieldName, validationInfo.minLen, validationInfo.maxLen, lengthUnit, dimensions.describe(), actualLen))
+		}
+	}
+	if validationInfo.pattern != nil && !validationInfo.pattern.MatchString(fieldValue) {
+		validationErrors = append(validationErrors, fmt.Sprintf(patternErrorFormat, fieldName, validationInfo.pattern, dimensions.describe(), fieldValue))
+	}
+	if len(validationInfo.oneOf) > 0 && !containsString(validationInfo.oneOf, fieldValue) {
+		validationErrors = append(validationErrors, fmt.Sprintf(oneOfErrorFormat, fieldName, strings.Join(validationInfo.oneOf, ", "), dimensions.describe(), fieldValue))
+	}
+	if validationInfo.charset != nil {
+		for _, disallowed := range validationInfo.charset.Disallowed(fieldValue) {
+			validationErrors = append(validationErrors, fmt.Sprintf(charsetErrorFormat, fieldName, validationInfo.charset.Name(), dimensions.describe(), disallowed.Rune, disallowed.Position))
+		}
+	}
+	return append(validationErrors, getComparisonErrors(dimensions, value, fieldName, field, validationInfo)...)
 }
 
-func getValidationErrors(country, fieldName, fieldValue string, validationInfo *CountryValidationInfo) []string {
+// getComparisonErrors compares a field of value with the fields its rules
+// name, which are of its kind. Strings are normalized, and empty strings and
+// zero times are not compared.
+func getComparisonErrors(dimensions Dimensions, value reflect.Value, fieldName string, field reflect.Value, validationInfo *CountryValidationInfo) []string {
 	var validationErrors []string = nil
-	if validationInfo.minLen > 0 && validationInfo.maxLen > 0 {
-		if validationInfo.minLen != validationInfo.maxLen {
-			actualLen := len(fieldValue)
-			if actualLen < validationInfo.minLen || actualLen > validationInfo.maxLen {
-				validationErrors = append(validationErrors, fmt.Sprintf("field %s must have size from %d to %d when country is GB but found size %d", fieldName, validationInfo.minLen, validationInfo.maxLen, actualLen))
-			}
+	for _, reference := range validationInfo.comparisons {
+		other := value.Field(reference.index)
+		comparison := fieldComparisons[reference.rule]
+		if result, compared := compareFields(field, other, validationInfo.normalizers); compared && !comparison.holds(result) {
+			validationErrors = append(validationErrors, fmt.Sprintf(comparisonErrorFormat, fieldName, comparison.verb, reference.name, dimensions.describe(),
+				formatField(field, validationInfo.normalizers), formatField(other, validationInfo.normalizers)))
 		}
 	}
-
 	return validationErrors
 }
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -12,6 +12,109 @@ type account struct {
 	IBAN    string `f3_validate:"[GB:8 | AU:4,required | PT:7-9,required]"`
 }
 
+func Test_ValidateChecksRequiredAndExactLengths(t *testing.T) {
+	cases := []struct {
+		description              string
+		account                  account
+		country                  string
+		expectedValidationErrors []string
+	}{
+		{
+			description:              "required value missing",
+			account:                  account{IBAN: "GB29NWBK"},
+			country:                  "GB",
+			expectedValidationErrors: []string{"field BankId is required when country is GB"},
+		},
+		{
+			description:              "exact length not met",
+			account:                  account{BankId: "1234567", IBAN: "GB29"},
+			country:                  "GB",
+			expectedValidationErrors: []string{"field IBAN must have size from 8 to 8 bytes when country is GB but found size 4"},
+		},
+		{
+			description:              "empty value without required",
+			account:                  account{BankId: "1234567"},
+			country:                  "GB",
+			expectedValidationErrors: nil,
+		},
+		{
+			description: "required values of another country",
+			account:     account{},
+			country:     "PT",
+			expectedValidationErrors: []string{
+				"field IBAN is required when country is PT",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			validationErrors, err := Validate(c.account, c.country)
+
+			assert.Nil(t, err)
+			assert.Equal(t, c.expectedValidationErrors, validationErrors)
+		})
+	}
+}
+
+func Test_ValidateChecksPatternAndOneOfOfNonEmptyValues(t *testing.T) {
+	cases := []struct {
+		description              string
+		transfer                 transfer
+		expectedValidationErrors []string
+	}{
+		{
+			description:              "values matching the rules",
+			transfer:                 transfer{Currency: "EUR", Reference: "INVOICE 42", Internal: "yes"},
+			expectedValidationErrors: nil,
+		},
+		{
+			description: "empty values are left to required",
+			transfer:    transfer{Reference: "A"},
+			expectedValidationErrors: []string{
+				"field Currency is required when country is GB",
+				"field Internal is required when country is GB",
+			},
+		},
+		{
+			description: "values not matching the rules",
+			transfer:    transfer{Currency: "USD", Reference: "invoice", Internal: "yes"},
+			expectedValidationErrors: []string{
+				"field Currency must be one of GBP, EUR when country is GB but found USD",
+				"field Reference must match ^[A-Z0-9 ]+$ when country is GB but found invoice",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			validationResult, err := Validate(c.transfer, "GB")
+
+			assert.Nil(t, err)
+			assert.ElementsMatch(t, c.expectedValidationErrors, validationResult)
+		})
+	}
+}
+
+func Test_ValidateReportsEachRuneOutsideTheCharset(t *testing.T) {
+	type beneficiary struct {
+		Name string `f3_validate:"[GB:charset(fps) | DE:charset(sepa)]"`
+	}
+
+	validationResult, err := Validate(beneficiary{Name: "Zoë & Jürgen"}, "DE")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"field Name must only use characters of charset sepa when country is DE but found 'ë' in position 2",
+		"field Name must only use characters of charset sepa when country is DE but found '&' in position 4",
+		"field Name must only use characters of charset sepa when country is DE but found 'ü' in position 7",
+	}, validationResult)
+
+	validationResult, err = Validate(beneficiary{Name: "Zoe & Jurgen"}, "GB")
+	assert.Nil(t, err)
+	assert.Nil(t, validationResult)
+}
+
 func Test_CreateValidationMatrix_CreatesExpectedValidationMatrix(t *testing.T) {
 	acc := &account{
 		Country: "GB",
@@ -36,7 +139,7 @@ func Test_GivenTestCase_WhenICallValidateMethod_ThenItReturnsExpectedResult(t *t
 		{
 			description:              "when account is invalid then validation returns expected validation errors",
 			acc:                      &account{Country: "GB", BankId: "123"},
-			expectedValidationErrors: []string{"field BankId must have size from 7 to 10 when country is GB but found size 3"},
+			expectedValidationErrors: []string{"field BankId must have size from 7 to 10 bytes when country is GB but found size 3"},
 		},
 	}
 
@@ -71,3 +174,25 @@ func Test_WhenICallValidateMethodWithTagsInWrongFormat_ThenIGetABuildError(t *te
 
 	assert.NotNil(t, err)
 }
+
+func Test_CreateValidationMatrixRejectsRulesOnFieldsOtherThanStrings(t *testing.T) {
+	type amount struct {
+		Amount int `f3_validate:"[GB:1-3]"`
+	}
+	type consent struct {
+		Given bool `f3_validate:"[GB:since(2026-03-01) | IE:required]"`
+	}
+
+	_, err := CreateValidationMatrix(amount{})
+	assert.NotNil(t, err)
+	assert.Equal(t, CodeUnsupportedField, err.(*TagError).Code)
+	assert.Equal(t, "field tags.amount.Amount (int): rule length cannot check a field of type int in position 4, expected string\n\t[GB:1-3]\n\t    ^", err.Error())
+
+	_, err = Validate(consent{}, "IE")
+	assert.NotNil(t, err)
+	assert.Equal(t, "rule required cannot check a field of type bool in position 27, expected string", err.(*TagError).Summary())
+
+	_, err = GenerateJSONSchema(amount{}, "GB", schemaAsOf)
+	assert.NotNil(t, err)
+	assert.Equal(t, CodeUnsupportedField, err.(*TagError).Code)
+}
@@ -0,0 +1,92 @@
+package tags
+
+import (
+	"reflect"
+	"sort"
+	"time"
+)
+
+// validationPlan is the runtime form of the rules of a type: its validated
+// fields by index in declaration order, each with its selectors most specific
+// first and the periods of each selector in a flat slice. Validate walks it
+// without looking anything up by name, so a valid struct is validated without
+// allocating.
+type validationPlan struct {
+	fields []fieldPlan
+}
+
+type fieldPlan struct {
+	name      string
+	index     int
+	selectors []selectorPlan
+}
+
+// selectorPlan holds the rules of a selector, e.g. GB/FPS, ordered by since.
+type selectorPlan struct {
+	terms      []dimensionTerm
+	dimensions Dimensions
+	periods    []CountryValidationInfo
+}
+
+type dimensionTerm struct {
+	name  string
+	value string
+}
+
+// newValidationPlan compiles the plan of a struct type from its matrix.
+func newValidationPlan(t reflect.Type, matrix ValidationMatrix) *validationPlan {
+	plan := &validationPlan{}
+	for index := 0; index < t.NumField(); index++ {
+		validationInfos := matrix[t.Field(index).Name]
+		if validationInfos == nil {
+			continue
+		}
+
+		field := fieldPlan{name: t.Field(index).Name, index: index}
+		for key, periods := range *validationInfos {
+			selector := selectorPlan{dimensions: keyDimensions(key)}
+			for _, name := range selector.dimensions.names() {
+				selector.terms = append(selector.terms, dimensionTerm{name: name, value: selector.dimensions[name]})
+			}
+			for period := periods; period != nil; period = period.next {
+				resolved := *period
+				resolved.requiredIf = period.requiredIf.resolve(t)
+				resolved.requiredUnless = period.requiredUnless.resolve(t)
+				resolved.comparisons = resolveAll(period.comparisons, t)
+				selector.periods = append(selector.periods, resolved)
+			}
+			field.selectors = append(field.selectors, selector)
+		}
+		sort.Slice(field.selectors, func(i, j int) bool {
+			return field.selectors[i].dimensions.moreSpecific(field.selectors[j].dimensions)
+		})
+		plan.fields = append(plan.fields, field)
+	}
+	return plan
+}
+
+// rulesFor returns the rules in effect at asOf of the most specific selector of
+// dimensions with rules at that time, as CountriesValidationInfos.rulesFor does.
+func (field *fieldPlan) rulesFor(dimensions Dimensions, asOf time.Time) *CountryValidationInfo {
+	for i := range field.selectors {
+		selector := &field.selectors[i]
+		if !selector.selects(dimensions) {
+			continue
+		}
+		for j := range selector.periods {
+			if period := &selector.periods[j]; period.inEffect(asOf) {
+				return period
+			}
+		}
+	}
+	return nil
+}
+
+func (selector *selectorPlan) selects(dimensions Dimensions) bool {
+	for _, term := range selector.terms {
+		if value, ok := dimensions[term.name]; !ok || value != term.value {
+			return false
+		}
+	}
+	return true
+}
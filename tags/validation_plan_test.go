@@ -0,0 +1,80 @@
+package tags
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ValidateAllocatesNothingForValidStructs(t *testing.T) {
+	rules, err := LoadRuleFile("testdata/rules/periods.yaml")
+	assert.Nil(t, err)
+
+	cases := []struct {
+		description string
+		validator   *Validator
+		value       interface{}
+		dimensions  Dimensions
+	}{
+		{
+			description: "rules of tags",
+			validator:   mustValidator(t),
+			value:       &account{BankId: "1234567", IBAN: "12345678"},
+			dimensions:  Dimensions{CountryDimension: "GB"},
+		},
+		{
+			description: "pattern and oneof",
+			validator:   mustValidator(t),
+			value:       &transfer{Currency: "GBP", Reference: "INVOICE 42", Internal: "yes"},
+			dimensions:  Dimensions{CountryDimension: "GB"},
+		},
+		{
+			description: "rules with effective dates",
+			validator:   mustValidator(t, WithRuleSet(rules), WithPrecedence(FileOverridesTag)),
+			value:       &account{BankId: "12345", IBAN: "1234567"},
+			dimensions:  Dimensions{CountryDimension: "PT"},
+		},
+		{
+			description: "most specific of several dimensions",
+			validator:   mustValidator(t),
+			value:       &charge{Reference: "12345678"},
+			dimensions:  Dimensions{CountryDimension: "GB", SchemeDimension: "FPS", "currency": "EUR"},
+		},
+		{
+			description: "struct value",
+			validator:   mustValidator(t),
+			value:       payee{SortCode: "123456", AccountNumber: "1234567"},
+			dimensions:  Dimensions{CountryDimension: "GB"},
+		},
+	}
+
+	ctx := ContextWithAsOf(context.Background(), time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC))
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			validationErrors, err := c.validator.Validate(ctx, c.value, c.dimensions)
+			assert.Nil(t, err)
+			assert.Nil(t, validationErrors)
+
+			allocs := testing.AllocsPerRun(100, func() {
+				c.validator.Validate(ctx, c.value, c.dimensions)
+			})
+			assert.Zero(t, allocs)
+		})
+	}
+}
+
+func Test_ValidationPlanOrdersSelectorsMostSpecificFirst(t *testing.T) {
+	matrix, err := CreateValidationMatrix(charge{})
+	assert.Nil(t, err)
+
+	plan := newValidationPlan(reflect.TypeOf(charge{}), matrix)
+
+	var keys []string
+	for _, selector := range plan.fields[0].selectors {
+		keys = append(keys, selector.dimensions.Key())
+	}
+	assert.Equal(t, []string{"GB/FPS,currency=EUR", "GB/FPS", "GB,currency=EUR", "GB", "currency=EUR"}, keys)
+}
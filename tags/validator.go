@@ -0,0 +1,401 @@
+package tags
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Precedence decides which rules apply when the tag of a field and a rule file
+// both give rules for the same country.
+type Precedence int
+
+const (
+	// TagOverridesFile keeps the rules of the tag, the rule file only adds
+	// countries and fields the tags do not mention.
+	TagOverridesFile Precedence = iota
+	// FileOverridesTag replaces the rules of the tag with those of the rule file.
+	FileOverridesTag
+	// MergeRules applies the rules of both, those of the rule file winning
+	// when both set the same rule or either has effective dates.
+	MergeRules
+)
+
+// Validator validates structs against their tags and the rules of rule files.
+// It caches the validation matrix and plan of each type and can reload its rule files
+// while validating: a reload compiles the new rules aside and swaps them in
+// with the cache at once, so Validate never waits for it.
+type Validator struct {
+	rules         RuleSet
+	ruleFiles     []string
+	precedence    Precedence
+	onReloadError func(error)
+	clock         func() time.Time
+	lengthUnit    LengthUnit
+
+	reloadMutex sync.Mutex
+	// loadedStamp is the ruleFilesStamp of the last reload, successful or not.
+	loadedStamp string
+	state       atomic.Value
+}
+
+// validatorState is what a reload swaps: the rules, their version and the
+// types compiled with them, by reflect.Type.
+type validatorState struct {
+	rules   RuleSet
+	version uint64
+	types   sync.Map
+}
+
+// compiledType is the validation matrix of a type and the plan Validate runs.
+type compiledType struct {
+	matrix ValidationMatrix
+	plan   *validationPlan
+}
+
+// ValidatorOption configures a Validator.
+type ValidatorOption func(*Validator)
+
+// WithRuleSet adds the rules of a rule file, on top of those already added.
+func WithRuleSet(rules RuleSet) ValidatorOption {
+	return func(validator *Validator) {
+		validator.rules = mergeRuleSets(validator.rules, rules)
+	}
+}
+
+// WithRuleFiles adds rule files, loaded when the validator is created and again
+// by Reload and Watch. Rules of later files replace those of earlier ones.
+func WithRuleFiles(paths ...string) ValidatorOption {
+	return func(validator *Validator) {
+		validator.ruleFiles = append(validator.ruleFiles, paths...)
+	}
+}
+
+// WithPrecedence sets how the rules of tags and rule files combine, TagOverridesFile by default.
+func WithPrecedence(precedence Precedence) ValidatorOption {
+	return func(validator *Validator) {
+		validator.precedence = precedence
+	}
+}
+
+// WithClock sets the clock Validate reads the time of the rules to apply from, time.Now by default.
+func WithClock(clock func() time.Time) ValidatorOption {
+	return func(validator *Validator) {
+		validator.clock = clock
+	}
+}
+
+// WithLengthUnit sets what the length rules without a unit count, Bytes by default.
+func WithLengthUnit(unit LengthUnit) ValidatorOption {
+	return func(validator *Validator) {
+		validator.lengthUnit = unit
+	}
+}
+
+// WithReloadErrorHandler sets the function told about rule files that fail to
+// reload, whose rules are then kept as they were. NewValidator returns the
+// error of the first load instead.
+func WithReloadErrorHandler(onReloadError func(error)) ValidatorOption {
+	return func(validator *Validator) {
+		validator.onReloadError = onReloadError
+	}
+}
+
+// NewValidator returns a Validator, which without options only uses the tags.
+// Its rule files are loaded right away, and an error is returned when one of
+// them fails to load, as there are no rules yet to fall back on. Later reloads
+// keep the current rules instead, see Reload.
+func NewValidator(options ...ValidatorOption) (*Validator, error) {
+	validator := newValidator(options...)
+	if len(validator.ruleFiles) > 0 {
+		validator.loadedStamp = validator.ruleFilesStamp()
+		loaded, err := validator.loadState(validator.currentState())
+		if err != nil {
+			return nil, err
+		}
+		validator.state.Store(loaded)
+	}
+	return validator, nil
+}
+
+// newValidator returns a Validator of options without loading its rule files.
+func newValidator(options ...ValidatorOption) *Validator {
+	validator := &Validator{rules: make(RuleSet), clock: time.Now, lengthUnit: Bytes}
+	for _, option := range options {
+		option(validator)
+	}
+	validator.state.Store(&validatorState{rules: validator.rules})
+	return validator
+}
+
+// Version counts the successful loads of the rule files, so it is 1 once
+// NewValidator loaded them and 0 without rule files.
+func (validator *Validator) Version() uint64 {
+	return validator.currentState().version
+}
+
+// Reload loads the rule files again. When one of them fails to load, or gives
+// rules for a field that does not exist in a type already validated, the
+// current rules are kept and the error is returned and passed to the reload
+// error handler.
+func (validator *Validator) Reload() error {
+	validator.reloadMutex.Lock()
+	defer validator.reloadMutex.Unlock()
+
+	validator.loadedStamp = validator.ruleFilesStamp()
+	current := validator.currentState()
+	next, err := validator.loadState(current)
+	if err != nil {
+		if validator.onReloadError != nil {
+			validator.onReloadError(err)
+		}
+		return err
+	}
+
+	validator.state.Store(next)
+	return nil
+}
+
+func (validator *Validator) loadState(current *validatorState) (*validatorState, error) {
+	rules := mergeRuleSets(nil, validator.rules)
+	for _, path := range validator.ruleFiles {
+		fileRules, err := LoadRuleFile(path)
+		if err != nil {
+			return nil, err
+		}
+		rules = mergeRuleSets(rules, fileRules)
+	}
+
+	next := &validatorState{rules: rules, version: current.version + 1}
+	var err error
+	current.types.Range(func(key, _ interface{}) bool {
+		var compiled *compiledType
+		if compiled, err = validator.compileType(next, key.(reflect.Type)); err == nil {
+			next.types.Store(key, compiled)
+		}
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// Watch checks the rule files every interval and reloads them when one of them
+// changed since the last reload, until ctx is done. A file that fails to load is
+// only reloaded once it changes again.
+func (validator *Validator) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			validator.reloadMutex.Lock()
+			changed := validator.ruleFilesStamp() != validator.loadedStamp
+			validator.reloadMutex.Unlock()
+			if changed {
+				validator.Reload()
+			}
+		}
+	}
+}
+
+// ruleFilesStamp changes whenever a rule file is modified, created or removed.
+func (validator *Validator) ruleFilesStamp() string {
+	var stamp strings.Builder
+	for _, path := range validator.ruleFiles {
+		if info, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&stamp, "%s %d %d\n", path, info.ModTime().UnixNano(), info.Size())
+		} else {
+			fmt.Fprintf(&stamp, "%s %v\n", path, err)
+		}
+	}
+	return stamp.String()
+}
+
+func (validator *Validator) currentState() *validatorState {
+	return validator.state.Load().(*validatorState)
+}
+
+// ValidationMatrix returns the rules of a struct, or pointer to struct, from
+// its tags and the rule files of the validator. The matrix is shared with
+// other callers and must not be modified.
+func (validator *Validator) ValidationMatrix(i interface{}) (ValidationMatrix, error) {
+	compiled, err := validator.compiledType(i)
+	if err != nil {
+		return nil, err
+	}
+	return compiled.matrix, nil
+}
+
+func (validator *Validator) compiledType(i interface{}) (*compiledType, error) {
+	t := reflect.TypeOf(i)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	state := validator.currentState()
+	if compiled, cached := state.types.Load(t); cached {
+		return compiled.(*compiledType), nil
+	}
+	compiled, err := validator.compileType(state, t)
+	if err != nil {
+		return nil, err
+	}
+	state.types.Store(t, compiled)
+	return compiled, nil
+}
+
+func (validator *Validator) compileType(state *validatorState, t reflect.Type) (*compiledType, error) {
+	matrix, err := validator.createValidationMatrix(state, t)
+	if err != nil {
+		return nil, err
+	}
+	return &compiledType{matrix: matrix, plan: newValidationPlan(t, matrix)}, nil
+}
+
+func (validator *Validator) createValidationMatrix(state *validatorState, t reflect.Type) (ValidationMatrix, error) {
+	matrix, err := CreateValidationMatrix(reflect.Zero(t).Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	for fieldName, fileValidationInfos := range state.rules[t.String()] {
+		if _, exists := t.FieldByName(fieldName); !exists {
+			return nil, fmt.Errorf("rule file gives rules for unknown field %s.%s", t, fieldName)
+		}
+
+		validationInfos := make(CountriesValidationInfos)
+		if tagValidationInfos := matrix[fieldName]; tagValidationInfos != nil {
+			for country, countryValidationInfo := range *tagValidationInfos {
+				validationInfos[country] = countryValidationInfo
+			}
+		}
+		for country, fileValidationInfo := range *fileValidationInfos {
+			tagValidationInfo := validationInfos[country]
+			switch {
+			case tagValidationInfo == nil || validator.precedence == FileOverridesTag:
+				validationInfos[country] = fileValidationInfo
+			case validator.precedence == MergeRules:
+				validationInfos[country] = tagValidationInfo.merge(fileValidationInfo)
+			}
+		}
+		matrix[fieldName] = &validationInfos
+	}
+
+	if err := CheckStructFields(t.String(), structFields(t), matrix); err != nil {
+		return nil, err
+	}
+	return matrix, nil
+}
+
+// asOfKey is the context key of the time set by ContextWithAsOf.
+type asOfKey struct{}
+
+// ContextWithAsOf returns a context under which Validate applies the rules in
+// effect at asOf instead of those in effect at the time of its clock.
+func ContextWithAsOf(ctx context.Context, asOf time.Time) context.Context {
+	return context.WithValue(ctx, asOfKey{}, asOf)
+}
+
+// AsOfFromContext returns the time set by ContextWithAsOf, if any.
+func AsOfFromContext(ctx context.Context) (time.Time, bool) {
+	asOf, ok := ctx.Value(asOfKey{}).(time.Time)
+	return asOf, ok
+}
+
+// Validate returns the validation errors of a struct for the given dimensions,
+// e.g. Dimensions{"country": "GB", "scheme": "FPS"}, with the rules in effect at
+// the time of the clock of the validator or the one of ContextWithAsOf. Each
+// field gets the rules of the most specific selector of its tag that matches:
+// the one naming the most dimensions, then the one naming the country, the
+// scheme or the first other dimension by name that the other lacks. Errors
+// come in the order of the fields.
+func (validator *Validator) Validate(ctx context.Context, i interface{}, dimensions Dimensions) ([]string, error) {
+	asOf, ok := AsOfFromContext(ctx)
+	if !ok {
+		asOf = validator.clock()
+	}
+
+	compiled, err := validator.compiledType(i)
+	var validationErrors []string = nil
+
+	if err != nil {
+		return nil, err
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(i))
+	for f := range compiled.plan.fields {
+		field := &compiled.plan.fields[f]
+		if countryValidationInfo := field.rulesFor(dimensions, asOf); countryValidationInfo != nil {
+			if errs := getValidationErrors(dimensions, value, field.name, value.Field(field.index), countryValidationInfo, validator.lengthUnit); errs != nil {
+				validationErrors = append(validationErrors, errs...)
+			}
+		}
+	}
+
+	return validationErrors, nil
+}
+
+// merge returns the rules of both infos, those set by override winning. Rules
+// with effective dates are not merged, override replaces them.
+func (countryValidationInfo *CountryValidationInfo) merge(override *CountryValidationInfo) *CountryValidationInfo {
+	if countryValidationInfo.isDated() || override.isDated() {
+		return override
+	}
+	merged := *countryValidationInfo
+	if override.minLen > 0 || override.maxLen > 0 {
+		merged.minLen, merged.maxLen, merged.lengthUnit = override.minLen, override.maxLen, override.lengthUnit
+	}
+	merged.required = merged.required || override.required
+	if override.pattern != nil {
+		merged.pattern = override.pattern
+	}
+	if len(override.oneOf) > 0 {
+		merged.oneOf = override.oneOf
+	}
+	if override.charset != nil {
+		merged.charset = override.charset
+	}
+	if len(override.normalizers) > 0 {
+		merged.normalizers, merged.normalizedAt = override.normalizers, override.normalizedAt
+	}
+	if merged.checkedAt == nil {
+		merged.checkedAt = override.checkedAt
+	}
+	if override.requiredIf != nil {
+		merged.requiredIf = override.requiredIf
+	}
+	if override.requiredUnless != nil {
+		merged.requiredUnless = override.requiredUnless
+	}
+	for _, comparison := range override.comparisons {
+		merged.comparisons = withComparison(merged.comparisons, comparison)
+	}
+	return &merged
+}
+
+// mergeRuleSets returns the rules of both sets, the fields of override
+// replacing those of base.
+func mergeRuleSets(base, override RuleSet) RuleSet {
+	merged := make(RuleSet)
+	for _, rules := range []RuleSet{base, override} {
+		for typeName, matrix := range rules {
+			if merged[typeName] == nil {
+				merged[typeName] = make(ValidationMatrix)
+			}
+			for fieldName, validationInfos := range matrix {
+				merged[typeName][fieldName] = validationInfos
+			}
+		}
+	}
+	return merged
+}
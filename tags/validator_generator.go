@@ -0,0 +1,292 @@
+package tags
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidatorsImportPath is the import path of this package in generated code.
+const ValidatorsImportPath = "sandbox.io/tags/tags"
+
+// WriteValidators writes a Go file of package packageName with a ValidateF3
+// method per table, which returns the validation errors of the type for a
+// country as Validate does, with the rules of the tags inlined instead of read
+// by reflection. The fields of the tables must be strings, numbers or times,
+// as Kinds tells. Rules with
+// effective dates are checked against the time of the call, and rules of
+// payment schemes and other dimensions are left out, as Validate leaves them
+// out for a country alone.
+func WriteValidators(w io.Writer, packageName string, tables ...*MatrixTable) error {
+	generator := &validatorGenerator{patternNames: make(map[string]bool)}
+	for _, table := range tables {
+		generator.writeValidator(table)
+	}
+
+	var source bytes.Buffer
+	fmt.Fprintf(&source, "// Code generated by f3tags generate; DO NOT EDIT.\n\npackage %s\n\nimport (\n", packageName)
+	for _, used := range []struct {
+		path string
+		used bool
+	}{{"cmp", generator.usesCmp}, {"fmt", generator.usesFmt}, {"regexp", len(generator.patterns) > 0}, {"time", generator.usesTime}} {
+		if used.used {
+			fmt.Fprintf(&source, "%q\n", used.path)
+		}
+	}
+	fmt.Fprintf(&source, "\n%q\n)\n", ValidatorsImportPath)
+	if len(generator.patterns) > 0 {
+		source.WriteString("\nvar (\n")
+		for _, pattern := range generator.patterns {
+			fmt.Fprintf(&source, "%s = regexp.MustCompile(%q)\n", pattern.name, pattern.expr)
+		}
+		source.WriteString(")\n")
+	}
+	source.Write(generator.body.Bytes())
+
+	formatted, err := format.Source(source.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(formatted)
+	return err
+}
+
+type validatorGenerator struct {
+	body         bytes.Buffer
+	patterns     []generatedPattern
+	patternNames map[string]bool
+	usesCmp      bool
+	usesFmt      bool
+	usesTime     bool
+}
+
+// generatedPattern is a package variable holding a compiled pattern rule.
+type generatedPattern struct {
+	name string
+	expr string
+}
+
+func (generator *validatorGenerator) writeValidator(table *MatrixTable) {
+	typeName := table.TypeName[strings.LastIndex(table.TypeName, ".")+1:]
+	receiver := strings.ToLower(typeName[:1])
+	countries := schemelessCountries(table.Matrix)
+
+	dated := false
+	for _, country := range countries {
+		for _, field := range table.Fields {
+			if validationInfos := table.Matrix[field]; validationInfos != nil && (*validationInfos)[country] != nil && (*validationInfos)[country].isDated() {
+				dated = true
+			}
+		}
+	}
+
+	body := &generator.body
+	fmt.Fprintf(body, "\n// ValidateF3 returns the validation errors of %s for a country, as tags.Validate does.\n", typeName)
+	fmt.Fprintf(body, "func (%s *%s) ValidateF3(country string) tags.ValidationErrors {\n", receiver, typeName)
+	body.WriteString("var validationErrors tags.ValidationErrors\n")
+	if dated {
+		generator.usesTime = true
+		body.WriteString("now := time.Now()\n")
+	}
+	if len(countries) > 0 {
+		body.WriteString("switch country {\n")
+		for _, country := range countries {
+			fmt.Fprintf(body, "case %q:\n", country)
+			for _, field := range table.Fields {
+				if validationInfos := table.Matrix[field]; validationInfos != nil && (*validationInfos)[country] != nil {
+					generator.writePeriods(typeName, receiver, field, country, table.kind(field), (*validationInfos)[country])
+				}
+			}
+		}
+		body.WriteString("}\n")
+	}
+	body.WriteString("return validationErrors\n}\n")
+}
+
+// writePeriods writes the checks of the rules of a field for a country, in a
+// switch on the time of the call when they have effective dates.
+func (generator *validatorGenerator) writePeriods(typeName, receiver, field, country string, kind FieldKind, periods *CountryValidationInfo) {
+	value := receiver + "." + field
+	if !periods.isDated() {
+		generator.writeChecks(typeName, receiver, value, field, country, kind, periods)
+		return
+	}
+
+	generator.body.WriteString("switch {\n")
+	for period := periods; period != nil; period = period.next {
+		var conditions []string
+		if !period.since.IsZero() {
+			conditions = append(conditions, "!now.Before("+goTime(period.since)+")")
+		}
+		if !period.until.IsZero() {
+			conditions = append(conditions, "now.Before("+goTime(period.until)+")")
+		}
+		fmt.Fprintf(&generator.body, "case %s:\n", strings.Join(conditions, " && "))
+		generator.writeChecks(typeName, receiver, value, field, country, kind, period)
+	}
+	generator.body.WriteString("}\n")
+}
+
+// writeChecks writes the checks getValidationErrors makes: those of the
+// required rules when the value is empty and those of the others when it is
+// not, or the comparisons of a field that is not a string.
+func (generator *validatorGenerator) writeChecks(typeName, receiver, value, field, country string, kind FieldKind, validationInfo *CountryValidationInfo) {
+	body := &generator.body
+	if kind != StringKind {
+		generator.writeComparisons(receiver, value, field, country, kind, validationInfo)
+		return
+	}
+
+	checksEmpty := validationInfo.required || validationInfo.requiredIf != nil || validationInfo.requiredUnless != nil
+	checksValue := validationInfo.maxLen > 0 || validationInfo.pattern != nil || len(validationInfo.oneOf) > 0 || validationInfo.charset != nil ||
+		len(validationInfo.comparisons) > 0
+	if !checksEmpty && !checksValue {
+		return
+	}
+	generator.usesFmt = true
+
+	normalizers := make([]string, 0, len(validationInfo.normalizers))
+	for _, name := range validationInfo.normalizers {
+		normalizers = append(normalizers, strconv.Quote(name))
+	}
+	if len(normalizers) > 0 {
+		fmt.Fprintf(body, "normalized%s := tags.ApplyNormalizers(%s, %s)\n", field, value, strings.Join(normalizers, ", "))
+		value = "normalized" + field
+	}
+
+	if checksEmpty {
+		fmt.Fprintf(body, "if %s == \"\" {\n", value)
+		generator.writeEmptyChecks(receiver, field, country, validationInfo)
+		if checksValue {
+			body.WriteString("} else {\n")
+			generator.writeValueChecks(typeName, receiver, value, field, country, normalizers, validationInfo)
+		}
+	} else {
+		fmt.Fprintf(body, "if %s != \"\" {\n", value)
+		generator.writeValueChecks(typeName, receiver, value, field, country, normalizers, validationInfo)
+	}
+	body.WriteString("}\n")
+}
+
+// writeEmptyChecks writes the checks of the required rules of an empty value.
+func (generator *validatorGenerator) writeEmptyChecks(receiver, field, country string, validationInfo *CountryValidationInfo) {
+	body := &generator.body
+	describe := Dimensions{CountryDimension: country}.describe()
+
+	if validationInfo.required {
+		fmt.Fprintf(body, "validationErrors = append(validationErrors, fmt.Sprintf(%q, %q, %q))\n", requiredErrorFormat, field, describe)
+		return
+	}
+	if reference := validationInfo.requiredIf; reference != nil {
+		fmt.Fprintf(body, "if tags.IsSet(%s.%s) {\n", receiver, reference.name)
+		fmt.Fprintf(body, "validationErrors = append(validationErrors, fmt.Sprintf(%q, %q, %q, %q))\n}\n", requiredIfErrorFormat, field, describe, reference.name)
+	}
+	if reference := validationInfo.requiredUnless; reference != nil {
+		fmt.Fprintf(body, "if !tags.IsSet(%s.%s) {\n", receiver, reference.name)
+		fmt.Fprintf(body, "validationErrors = append(validationErrors, fmt.Sprintf(%q, %q, %q, %q))\n}\n", requiredUnlessErrorFormat, field, describe, reference.name)
+	}
+}
+
+// writeValueChecks writes the checks of the other rules of a value that is
+// not empty.
+func (generator *validatorGenerator) writeValueChecks(typeName, receiver, value, field, country string, normalizers []string, validationInfo *CountryValidationInfo) {
+	body := &generator.body
+	describe := Dimensions{CountryDimension: country}.describe()
+
+	if validationInfo.maxLen > 0 {
+		// Without a unit lengths are counted in bytes, as the default Validator counts them.
+		count, unit := fmt.Sprintf("len(%s)", value), Bytes
+		switch validationInfo.lengthUnit {
+		case Runes:
+			count, unit = fmt.Sprintf("tags.Runes.Count(%s)", value), Runes
+		case Graphemes:
+			count, unit = fmt.Sprintf("tags.Graphemes.Count(%s)", value), Graphemes
+		}
+		fmt.Fprintf(body, "if length := %s; length < %d || length > %d {\n", count, validationInfo.minLen, validationInfo.maxLen)
+		fmt.Fprintf(body, "validationErrors = append(validationErrors, fmt.Sprintf(%q, %q, %d, %d, %q, %q, length))\n}\n", lengthErrorFormat, field, validationInfo.minLen, validationInfo.maxLen, unit, describe)
+	}
+
+	if validationInfo.pattern != nil {
+		pattern := generator.addPattern("f3"+typeName+field+country+"Pattern", validationInfo.pattern.String())
+		fmt.Fprintf(body, "if !%s.MatchString(%s) {\n", pattern, value)
+		fmt.Fprintf(body, "validationErrors = append(validationErrors, fmt.Sprintf(%q, %q, %s, %q, %s))\n}\n", patternErrorFormat, field, pattern, describe, value)
+	}
+
+	if len(validationInfo.oneOf) > 0 {
+		var accepted []string
+		for _, oneOf := range validationInfo.oneOf {
+			if quoted := strconv.Quote(oneOf); oneOf != "" && !containsString(accepted, quoted) {
+				accepted = append(accepted, quoted)
+			}
+		}
+		if len(accepted) > 0 {
+			fmt.Fprintf(body, "switch %s {\ncase %s:\ndefault:\n", value, strings.Join(accepted, ", "))
+		} else {
+			body.WriteString("{\n")
+		}
+		fmt.Fprintf(body, "validationErrors = append(validationErrors, fmt.Sprintf(%q, %q, %q, %q, %s))\n}\n", oneOfErrorFormat, field, strings.Join(validationInfo.oneOf, ", "), describe, value)
+	}
+
+	if validationInfo.charset != nil {
+		fmt.Fprintf(body, "for _, disallowed := range tags.MustLookupCharset(%q).Disallowed(%s) {\n", validationInfo.charset.Name(), value)
+		fmt.Fprintf(body, "validationErrors = append(validationErrors, fmt.Sprintf(%q, %q, %q, %q, disallowed.Rune, disallowed.Position))\n}\n", charsetErrorFormat, field, validationInfo.charset.Name(), describe)
+	}
+
+	for _, reference := range validationInfo.comparisons {
+		comparison := fieldComparisons[reference.rule]
+		other := receiver + "." + reference.name
+		if len(normalizers) > 0 {
+			other = fmt.Sprintf("tags.ApplyNormalizers(%s, %s)", other, strings.Join(normalizers, ", "))
+		}
+		fmt.Fprintf(body, "if other := %s; other != \"\" && !(%s %s other) {\n", other, value, comparison.operator)
+		fmt.Fprintf(body, "validationErrors = append(validationErrors, fmt.Sprintf(%q, %q, %q, %q, %q, %s, other))\n}\n", comparisonErrorFormat, field, comparison.verb, reference.name, describe, value)
+	}
+}
+
+// writeComparisons writes the comparisons of a number or a time, which are
+// compared as compareFields compares them and printed as formatField prints them.
+func (generator *validatorGenerator) writeComparisons(receiver, value, field, country string, kind FieldKind, validationInfo *CountryValidationInfo) {
+	body := &generator.body
+	describe := Dimensions{CountryDimension: country}.describe()
+
+	for _, reference := range validationInfo.comparisons {
+		generator.usesFmt = true
+		comparison := fieldComparisons[reference.rule]
+		condition := fmt.Sprintf("!(cmp.Compare(%s, other) %s 0)", value, comparison.operator)
+		// Numbers are printed as the widest type of their kind, leaving out
+		// the String methods of named types, as formatField prints them.
+		conversion := map[FieldKind]string{IntKind: "int64", UintKind: "uint64", FloatKind: "float64"}[kind]
+		found, otherFound := fmt.Sprintf("fmt.Sprint(%s(%s))", conversion, value), fmt.Sprintf("fmt.Sprint(%s(other))", conversion)
+		if kind == TimeKind {
+			generator.usesTime = true
+			condition = fmt.Sprintf("!%s.IsZero() && !other.IsZero() && !(%s.Compare(other) %s 0)", value, value, comparison.operator)
+			found, otherFound = value+".Format(time.RFC3339Nano)", "other.Format(time.RFC3339Nano)"
+		} else {
+			generator.usesCmp = true
+		}
+		fmt.Fprintf(body, "if other := %s.%s; %s {\n", receiver, reference.name, condition)
+		fmt.Fprintf(body, "validationErrors = append(validationErrors, fmt.Sprintf(%q, %q, %q, %q, %q, %s, %s))\n}\n", comparisonErrorFormat, field, comparison.verb, reference.name, describe, found, otherFound)
+	}
+}
+
+// addPattern returns the name of a new variable holding a pattern, numbered
+// when name is taken by another period.
+func (generator *validatorGenerator) addPattern(name, expr string) string {
+	unique := name
+	for n := 2; generator.patternNames[unique]; n++ {
+		unique = name + strconv.Itoa(n)
+	}
+	generator.patternNames[unique] = true
+	generator.patterns = append(generator.patterns, generatedPattern{name: unique, expr: expr})
+	return unique
+}
+
+// goTime returns the Go expression of a time, in UTC.
+func goTime(t time.Time) string {
+	t = t.UTC()
+	return fmt.Sprintf("time.Date(%d, %d, %d, %d, %d, %d, %d, time.UTC)", t.Year(), int(t.Month()), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond())
+}
@@ -0,0 +1,46 @@
+package tags
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WriteValidatorsInlinesRulesOfCountries(t *testing.T) {
+	table, err := NewMatrixTable(payee{})
+	assert.Nil(t, err)
+
+	var source bytes.Buffer
+	assert.Nil(t, WriteValidators(&source, "payees", table))
+
+	assert.Equal(t, `// Code generated by f3tags generate; DO NOT EDIT.
+
+package payees
+
+import (
+	"fmt"
+
+	"sandbox.io/tags/tags"
+)
+
+// ValidateF3 returns the validation errors of payee for a country, as tags.Validate does.
+func (p *payee) ValidateF3(country string) tags.ValidationErrors {
+	var validationErrors tags.ValidationErrors
+	switch country {
+	case "GB":
+		if p.SortCode != "" {
+			if length := len(p.SortCode); length < 6 || length > 6 {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must have size from %d to %d %s when %s but found size %d", "SortCode", 6, 6, "bytes", "country is GB", length))
+			}
+		}
+		if p.AccountNumber != "" {
+			if length := len(p.AccountNumber); length < 6 || length > 8 {
+				validationErrors = append(validationErrors, fmt.Sprintf("field %s must have size from %d to %d %s when %s but found size %d", "AccountNumber", 6, 8, "bytes", "country is GB", length))
+			}
+		}
+	}
+	return validationErrors
+}
+`, source.String())
+}
@@ -0,0 +1,380 @@
+package tags
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ValidatorCombinesTagsAndRuleFilesByPrecedence(t *testing.T) {
+	rules, err := LoadRuleFile("testdata/rules/accounts.yaml")
+	assert.Nil(t, err)
+
+	cases := []struct {
+		description      string
+		precedence       Precedence
+		expectedBankIdGB string
+	}{
+		{description: "tag overrides file", precedence: TagOverridesFile, expectedBankIdGB: "7-10,required"},
+		{description: "file overrides tag", precedence: FileOverridesTag, expectedBankIdGB: "6-8"},
+		{description: "merge", precedence: MergeRules, expectedBankIdGB: "6-8,required"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			matrix, err := mustValidator(t, WithRuleSet(rules), WithPrecedence(c.precedence)).ValidationMatrix(&account{})
+
+			assert.Nil(t, err)
+			assert.Equal(t, c.expectedBankIdGB, (*matrix["BankId"])["GB"].String())
+			assert.Equal(t, "5", (*matrix["BankId"])["PT"].String(), "countries of the tag are kept")
+			assert.Equal(t, "8,required", (*matrix["BankId"])["IE"].String(), "countries of the file are added")
+			assert.Equal(t, "oneof(GB)", (*matrix["Country"])["GB"].String(), "fields of the file are added")
+			assert.Equal(t, "[AU:4,required | GB:8 | PT:7-9,required]", matrix["IBAN"].String(), "fields of the tag are kept")
+		})
+	}
+}
+
+func Test_ValidatorValidatesWithRuleFiles(t *testing.T) {
+	rules, err := LoadRuleFile("testdata/rules/accounts.json")
+	assert.Nil(t, err)
+	validator := mustValidator(t, WithRuleSet(rules), WithPrecedence(FileOverridesTag))
+
+	validationErrors, err := validator.Validate(context.Background(), account{Country: "FR", BankId: "123456789"}, Dimensions{CountryDimension: "GB"})
+
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{
+		"field BankId must have size from 6 to 8 bytes when country is GB but found size 9",
+		"field Country must be one of GB when country is GB but found FR",
+	}, validationErrors)
+}
+
+func Test_ValidatorRejectsRulesOfUnknownFields(t *testing.T) {
+	rules, err := ParseRuleFile([]byte("types:\n  tags.account:\n    Owner:\n      GB: required\n"), YAMLDocument)
+	assert.Nil(t, err)
+
+	_, err = mustValidator(t, WithRuleSet(rules)).Validate(context.Background(), account{}, Dimensions{CountryDimension: "GB"})
+
+	assert.EqualError(t, err, "rule file gives rules for unknown field tags.account.Owner")
+}
+
+func Test_ValidatorWithoutRuleFilesUsesTags(t *testing.T) {
+	matrix, err := mustValidator(t).ValidationMatrix(account{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "[GB:7-10,required | PT:5]", matrix["BankId"].String())
+}
+
+func Test_ValidatorCountsLengthsInUnits(t *testing.T) {
+	type beneficiary struct {
+		Name      string `f3_validate:"[GB:1-6 | IE:graphemes:1-6]"`
+		Reference string `f3_validate:"[GB:bytes:1-6]"`
+	}
+	value := beneficiary{Name: "Müllér", Reference: "Müllér"}
+
+	cases := []struct {
+		description    string
+		validator      *Validator
+		country        string
+		expectedErrors []string
+	}{
+		{
+			description: "bytes by default",
+			validator:   mustValidator(t),
+			country:     "GB",
+			expectedErrors: []string{
+				"field Name must have size from 1 to 6 bytes when country is GB but found size 9",
+				"field Reference must have size from 1 to 6 bytes when country is GB but found size 9",
+			},
+		},
+		{
+			description: "units of the validator for lengths without one",
+			validator:   mustValidator(t, WithLengthUnit(Runes)),
+			country:     "GB",
+			expectedErrors: []string{
+				"field Name must have size from 1 to 6 runes when country is GB but found size 7",
+				"field Reference must have size from 1 to 6 bytes when country is GB but found size 9",
+			},
+		},
+		{
+			description:    "units of the tag",
+			validator:      mustValidator(t, WithLengthUnit(Runes)),
+			country:        "IE",
+			expectedErrors: nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			validationErrors, err := c.validator.Validate(context.Background(), value, Dimensions{CountryDimension: c.country})
+
+			assert.Nil(t, err)
+			assert.Equal(t, c.expectedErrors, validationErrors)
+		})
+	}
+}
+
+func Test_ValidatorReloadsRuleFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	assert.Nil(t, os.WriteFile(path, []byte("types:\n  tags.account:\n    BankId:\n      IE: 8\n"), 0o644))
+
+	var reloadErrors []error
+	validator := mustValidator(t, WithRuleFiles(path), WithReloadErrorHandler(func(err error) { reloadErrors = append(reloadErrors, err) }))
+	matrix, err := validator.ValidationMatrix(account{})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), validator.Version())
+	assert.Equal(t, "8", (*matrix["BankId"])["IE"].String())
+
+	assert.Nil(t, os.WriteFile(path, []byte("types:\n  tags.account:\n    BankId:\n      IE: 9,required\n"), 0o644))
+	assert.Nil(t, validator.Reload())
+	matrix, err = validator.ValidationMatrix(account{})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), validator.Version())
+	assert.Equal(t, "9,required", (*matrix["BankId"])["IE"].String())
+
+	for _, rules := range []string{
+		"types:\n  tags.account:\n    BankId:\n      IE: mandatory\n",
+		"types:\n  tags.account:\n    Owner:\n      IE: required\n",
+	} {
+		assert.Nil(t, os.WriteFile(path, []byte(rules), 0o644))
+		assert.NotNil(t, validator.Reload())
+	}
+	matrix, err = validator.ValidationMatrix(account{})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), validator.Version(), "failed reloads keep the version")
+	assert.Equal(t, "9,required", (*matrix["BankId"])["IE"].String(), "failed reloads keep the rules")
+	assert.Len(t, reloadErrors, 2)
+}
+
+func Test_NewValidatorReturnsErrorsOfFirstLoad(t *testing.T) {
+	var reloadErrors []error
+	validator, err := NewValidator(WithRuleFiles("testdata/rules/missing.yaml"), WithReloadErrorHandler(func(err error) { reloadErrors = append(reloadErrors, err) }))
+
+	assert.Nil(t, validator)
+	assert.NotNil(t, err)
+	assert.Empty(t, reloadErrors, "the handler is only told about reloads")
+
+	validator, err = NewValidator()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), validator.Version())
+}
+
+// mustValidator returns a new validator of options, failing the test when its
+// rule files do not load.
+func mustValidator(t testing.TB, options ...ValidatorOption) *Validator {
+	t.Helper()
+	validator, err := NewValidator(options...)
+	if err != nil {
+		t.Fatalf("NewValidator: %v", err)
+	}
+	return validator
+}
+
+func Test_ValidatorWatchReloadsChangedRuleFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	assert.Nil(t, os.WriteFile(path, []byte(`{"types": {"tags.account": {"BankId": {"IE": "8"}}}}`), 0o644))
+	validator := mustValidator(t, WithRuleFiles(path))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go validator.Watch(ctx, time.Millisecond)
+
+	assert.Nil(t, os.WriteFile(path, []byte(`{"types": {"tags.account": {"BankId": {"IE": "8,required"}}}}`), 0o644))
+	assert.Eventually(t, func() bool { return validator.Version() == 2 }, time.Second, time.Millisecond)
+}
+
+func Test_ValidatorValidatesWhileReloading(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	assert.Nil(t, os.WriteFile(path, []byte("types:\n  tags.account:\n    BankId:\n      IE: 8\n"), 0o644))
+	validator := mustValidator(t, WithRuleFiles(path))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, err := validator.Validate(context.Background(), account{BankId: "123"}, Dimensions{CountryDimension: "IE"})
+				assert.Nil(t, err)
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, validator.Reload())
+	}
+	wg.Wait()
+
+	assert.Equal(t, uint64(11), validator.Version())
+}
+
+func Test_ValidatorAppliesRulesInEffect(t *testing.T) {
+	rules, err := LoadRuleFile("testdata/rules/periods.yaml")
+	assert.Nil(t, err)
+
+	cases := []struct {
+		description              string
+		country                  string
+		asOf                     time.Time
+		expectedValidationErrors []string
+	}{
+		{
+			description:              "last moment of the old rules",
+			country:                  "GB",
+			asOf:                     time.Date(2026, 2, 28, 23, 59, 59, 999999999, time.UTC),
+			expectedValidationErrors: []string{"field BankId must have size from 6 to 8 bytes when country is GB but found size 9"},
+		},
+		{
+			description:              "first moment of the new rules",
+			country:                  "GB",
+			asOf:                     time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			expectedValidationErrors: nil,
+		},
+		{
+			description:              "before the first rules",
+			country:                  "PT",
+			asOf:                     time.Date(2026, 3, 1, 10, 59, 59, 0, time.UTC),
+			expectedValidationErrors: nil,
+		},
+		{
+			description:              "since in another time zone",
+			country:                  "PT",
+			asOf:                     time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC),
+			expectedValidationErrors: []string{"field BankId must have size from 4 to 5 bytes when country is PT but found size 9"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			asOf := c.asOf
+			validator := mustValidator(t, WithRuleSet(rules), WithPrecedence(FileOverridesTag), WithClock(func() time.Time { return asOf }))
+			acc := account{BankId: "123456789", IBAN: "12345678"}
+
+			validationErrors, err := validator.Validate(context.Background(), acc, Dimensions{CountryDimension: c.country})
+			assert.Nil(t, err)
+			validationErrorsAt, err := mustValidator(t, WithRuleSet(rules), WithPrecedence(FileOverridesTag)).Validate(ContextWithAsOf(context.Background(), asOf), acc, Dimensions{CountryDimension: c.country})
+			assert.Nil(t, err)
+
+			assert.Equal(t, validationErrors, validationErrorsAt)
+			if c.expectedValidationErrors == nil {
+				assert.Nil(t, validationErrors)
+			}
+			for _, expectedValidationErr := range c.expectedValidationErrors {
+				assert.Contains(t, validationErrors, expectedValidationErr)
+			}
+		})
+	}
+}
+
+func Test_MergeRulesLetsDatedRulesReplaceTags(t *testing.T) {
+	rules, err := LoadRuleFile("testdata/rules/periods.yaml")
+	assert.Nil(t, err)
+
+	matrix, err := mustValidator(t, WithRuleSet(rules), WithPrecedence(MergeRules)).ValidationMatrix(account{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "[GB:6-8,until(2026-03-01) | GB:6-10,since(2026-03-01) | PT:4-5,required,since('2026-03-01T12:00:00+01:00')]", matrix["BankId"].String())
+}
+
+type payee struct {
+	SortCode      string `f3_validate:"[GB:6]"`
+	AccountNumber string `f3_validate:"[GB:6-8 | GB/FPS:8-8,required | GB/BACS:7-8]"`
+}
+
+func Test_ValidateSchemeFallsBackToCountryRules(t *testing.T) {
+	cases := []struct {
+		description              string
+		scheme                   string
+		expectedValidationErrors []string
+	}{
+		{
+			description:              "country rules without scheme",
+			scheme:                   "",
+			expectedValidationErrors: nil,
+		},
+		{
+			description:              "scheme rules",
+			scheme:                   "BACS",
+			expectedValidationErrors: []string{"field AccountNumber must have size from 7 to 8 bytes when country is GB/BACS but found size 6"},
+		},
+		{
+			description:              "scheme without rules of its own",
+			scheme:                   "CHAPS",
+			expectedValidationErrors: nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			validationErrors, err := ValidateScheme(payee{SortCode: "123456", AccountNumber: "123456"}, "GB", c.scheme)
+
+			assert.Nil(t, err)
+			assert.Equal(t, c.expectedValidationErrors, validationErrors)
+		})
+	}
+}
+
+func Test_ValidateSchemeFallsBackWhenSchemeRulesAreNotInEffect(t *testing.T) {
+	validationInfos, err := CompileCountriesValidationInfos("[GB:6-8 | GB/FPS:7-8,since(2026-03-01)]")
+	assert.Nil(t, err)
+
+	assert.Equal(t, 6, validationInfos.rulesFor(Dimensions{CountryDimension: "GB", SchemeDimension: "FPS"}, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)).minLen)
+	assert.Equal(t, 7, validationInfos.rulesFor(Dimensions{CountryDimension: "GB", SchemeDimension: "FPS"}, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)).minLen)
+	assert.Nil(t, validationInfos.rulesFor(Dimensions{CountryDimension: "PT", SchemeDimension: "FPS"}, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+type charge struct {
+	Reference string `f3_validate:"[GB:4-18 | currency=EUR:4-35 | GB,currency=EUR:6-35 | GB/FPS:4-12 | GB/FPS,currency=EUR:8-12]"`
+}
+
+func Test_ValidatorAppliesRulesOfMostSpecificSelector(t *testing.T) {
+	cases := []struct {
+		description              string
+		dimensions               Dimensions
+		expectedValidationErrors []string
+	}{
+		{
+			description:              "country alone",
+			dimensions:               Dimensions{"country": "GB"},
+			expectedValidationErrors: []string{"field Reference must have size from 4 to 18 bytes when country is GB but found size 3"},
+		},
+		{
+			description:              "other dimension alone",
+			dimensions:               Dimensions{"country": "PT", "currency": "EUR"},
+			expectedValidationErrors: []string{"field Reference must have size from 4 to 35 bytes when country is PT and currency is EUR but found size 3"},
+		},
+		{
+			description:              "country before other dimensions when as specific",
+			dimensions:               Dimensions{"country": "GB", "currency": "USD", "scheme": "FPS"},
+			expectedValidationErrors: []string{"field Reference must have size from 4 to 12 bytes when country is GB/FPS and currency is USD but found size 3"},
+		},
+		{
+			description:              "most dimensions",
+			dimensions:               Dimensions{"country": "GB", "currency": "EUR", "scheme": "FPS"},
+			expectedValidationErrors: []string{"field Reference must have size from 8 to 12 bytes when country is GB/FPS and currency is EUR but found size 3"},
+		},
+		{
+			description:              "scheme before other dimensions when as specific",
+			dimensions:               Dimensions{"country": "GB", "currency": "EUR", "scheme": "BACS"},
+			expectedValidationErrors: []string{"field Reference must have size from 6 to 35 bytes when country is GB/BACS and currency is EUR but found size 3"},
+		},
+		{
+			description:              "no matching selector",
+			dimensions:               Dimensions{"currency": "GBP"},
+			expectedValidationErrors: nil,
+		},
+	}
+
+	validator := mustValidator(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			validationErrors, err := validator.Validate(context.Background(), charge{Reference: "123"}, c.dimensions)
+
+			assert.Nil(t, err)
+			assert.Equal(t, c.expectedValidationErrors, validationErrors)
+		})
+	}
+}